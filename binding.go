@@ -0,0 +1,438 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	providerv1alpha1 "github.com/crossplane-contrib/provider-kubernetes/apis/object/v1alpha1"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
+	fnv1beta1 "github.com/crossplane/function-sdk-go/proto/v1beta1"
+	"github.com/crossplane/function-sdk-go/resource"
+	"github.com/crossplane/function-sdk-go/resource/composed"
+	"github.com/crossplane/function-sdk-go/response"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/st3v/servicebinding-decorator/input/v1alpha1"
+)
+
+// bindingConfig is the configuration of a single binding
+type bindingConfig struct {
+	// name is the name of the binding, empty unless a list of bindings is configured
+	name string
+
+	// primary specifies whether status.binding.name refers to the binding
+	primary bool
+
+	// statusFieldPath is an additional field of the XR the name of the binding secret is published to
+	statusFieldPath string
+
+	// cfg is the configuration of the binding, including the configuration it inherits
+	cfg v1alpha1.Config
+
+	// extraKeys are binding entries the claim specifies, they are only set if the binding does not have them
+	extraKeys map[string]string
+}
+
+// composedBinding is a binding whose binding secret has been composed
+type composedBinding struct {
+	name            string
+	secretName      string
+	statusFieldPath string
+	data            map[string][]byte
+}
+
+// compositeReference returns a reference to the supplied XR in its own namespace or, for cluster scoped XRs,
+// in the target namespace of XRs without a claim
+// without a claim, the XR itself is the provisioned service and takes the place of the claim
+// nil is returned if the XR is cluster scoped and no target namespace is configured or the XR does not specify one
+func compositeReference(cfg *v1alpha1.TargetNamespace, xr *resource.Composite) (*claim.Reference, error) {
+	// namespaced XRs never have a claim, they are bound in their own namespace
+	if ns := xr.Resource.GetNamespace(); ns != "" {
+		return &claim.Reference{
+			APIVersion: xr.Resource.GetAPIVersion(),
+			Kind:       xr.Resource.GetKind(),
+			Name:       xr.Resource.GetName(),
+			Namespace:  ns,
+		}, nil
+	}
+
+	if cfg == nil {
+		return nil, nil
+	}
+
+	namespace := ""
+	if cfg.Annotation != "" {
+		namespace = xr.Resource.GetAnnotations()[cfg.Annotation]
+	}
+
+	if namespace == "" && cfg.FieldPath != "" {
+		v, err := xr.Resource.GetString(cfg.FieldPath)
+		if err != nil && !fieldpath.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "cannot get target namespace from field %q of composite resource", cfg.FieldPath)
+		}
+		namespace = v
+	}
+
+	if namespace == "" {
+		namespace = cfg.Name
+	}
+
+	if namespace == "" {
+		return nil, nil
+	}
+
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return nil, errors.Errorf("invalid target namespace %q: %s", namespace, strings.Join(errs, ", "))
+	}
+
+	return &claim.Reference{
+		APIVersion: xr.Resource.GetAPIVersion(),
+		Kind:       xr.Resource.GetKind(),
+		Name:       xr.Resource.GetName(),
+		Namespace:  namespace,
+	}, nil
+}
+
+// isComposite returns whether the supplied reference refers to the XR itself rather than to its claim
+func isComposite(c *claim.Reference, xr *resource.Composite) bool {
+	return c.APIVersion == xr.Resource.GetAPIVersion() && c.Kind == xr.Resource.GetKind() && c.Name == xr.Resource.GetName()
+}
+
+// bindingConfigs returns the configurations of the bindings to compose
+// this is the supplied configuration itself unless it specifies a list of bindings
+// the supplied extra entries of the claim apply to every binding
+func bindingConfigs(cfg v1alpha1.Config, extraKeys map[string]string) ([]bindingConfig, error) {
+	if len(cfg.Bindings) == 0 {
+		return []bindingConfig{{primary: true, cfg: cfg, extraKeys: extraKeys}}, nil
+	}
+
+	configs := make([]bindingConfig, 0, len(cfg.Bindings))
+	primary := -1
+	names := map[string]bool{}
+
+	for i, b := range cfg.Bindings {
+		if b.Name == "" {
+			return nil, errors.Errorf("binding %d does not have a name", i)
+		}
+
+		if names[b.Name] {
+			return nil, errors.Errorf("binding %q is specified more than once", b.Name)
+		}
+		names[b.Name] = true
+
+		if b.Primary {
+			if primary >= 0 {
+				return nil, errors.Errorf("bindings %q and %q cannot both be the primary binding", cfg.Bindings[primary].Name, b.Name)
+			}
+			primary = i
+		}
+
+		configs = append(configs, bindingConfig{
+			name:            b.Name,
+			statusFieldPath: b.StatusFieldPath,
+			cfg:             inheritBindingConfig(cfg, b),
+			extraKeys:       extraKeys,
+		})
+	}
+
+	if primary < 0 {
+		primary = 0
+	}
+	configs[primary].primary = true
+
+	return configs, nil
+}
+
+// inheritBindingConfig returns the configuration of the supplied binding
+// settings of the binding replace the inherited ones, overrides and templates are merged with the inherited ones
+func inheritBindingConfig(cfg v1alpha1.Config, b v1alpha1.Binding) v1alpha1.Config {
+	inherited := *cfg.DeepCopy()
+	inherited.Bindings = nil
+
+	if b.ConnectionDetailsMode != "" {
+		inherited.ConnectionDetailsMode = b.ConnectionDetailsMode
+	}
+
+	if b.BindingKeys != nil {
+		inherited.BindingKeys = b.BindingKeys
+	}
+
+	inherited.BindingSecretOverrides = mergeStrings(inherited.BindingSecretOverrides, b.BindingSecretOverrides)
+	inherited.BindingSecretTemplates = mergeStrings(inherited.BindingSecretTemplates, b.BindingSecretTemplates)
+
+	inherited.SecretName = b.SecretName
+	if inherited.SecretName == nil {
+		inherited.SecretName = &v1alpha1.SecretName{Strategy: v1alpha1.SecretNameStrategyUID, Suffix: "-" + b.Name}
+	}
+
+	inherited.ResourceName = b.ResourceName
+	if inherited.ResourceName == "" {
+		inherited.ResourceName = string(bindingSecretResourceName(cfg)) + "-" + b.Name
+	}
+
+	return inherited
+}
+
+// mergeStrings returns the entries of base and overlay, entries of overlay win
+func mergeStrings(base, overlay map[string]string) map[string]string {
+	if len(overlay) == 0 {
+		return base
+	}
+
+	merged := make(map[string]string, len(base)+len(overlay))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range overlay {
+		merged[k] = v
+	}
+	return merged
+}
+
+// composeNamedBindingSecret composes the binding secret of the supplied binding
+// results of named bindings are prefixed with the name of the binding
+func composeNamedBindingSecret(b bindingConfig, xr *resource.Composite, c *claim.Reference, connSecretRef *xpv1.SecretReference, observed map[resource.Name]resource.ObservedComposed, desiredComposed map[resource.Name]*resource.DesiredComposed, rsp *fnv1beta1.RunFunctionResponse) (*composedBinding, bool) {
+	brsp := &fnv1beta1.RunFunctionResponse{}
+	cb, ok := composeBindingSecret(b.cfg, b.extraKeys, xr, c, connSecretRef, observed, desiredComposed, brsp)
+
+	for _, r := range brsp.GetResults() {
+		if b.name != "" {
+			r.Message = fmt.Sprintf("binding %q: %s", b.name, r.Message)
+		}
+		rsp.Results = append(rsp.GetResults(), r)
+	}
+
+	if cb != nil {
+		cb.name = b.name
+		cb.statusFieldPath = b.statusFieldPath
+	}
+
+	return cb, ok
+}
+
+// composeBindingSecret adds the binding secret, or the provider-kubernetes Object managing it, to the supplied desired composed resources
+// the binding is read from the connection details of the observed composed resources or, if connSecretRef is not nil,
+// from the connection details of the XR that are written to the claim's connection secret connSecretRef refers to
+// the supplied extra entries have the lowest precedence, they are only set if the binding does not have them otherwise
+// nil is returned if the binding secret cannot be composed yet
+// false is returned if the function must not proceed, in which case a fatal result has been added to the response
+func composeBindingSecret(cfg v1alpha1.Config, extraKeys map[string]string, oxr *resource.Composite, claim *claim.Reference, connSecretRef *xpv1.SecretReference, observed map[resource.Name]resource.ObservedComposed, desiredComposed map[resource.Name]*resource.DesiredComposed, rsp *fnv1beta1.RunFunctionResponse) (*composedBinding, bool) {
+	details := newBindingDetails()
+	switch {
+	case cfg.ConnectionDetailsMode == v1alpha1.ConnectionDetailsModeMapped:
+	case connSecretRef != nil:
+		// the binding secret enriches the claim's connection secret, hence it is based on the same connection details
+		mergeCompositeConnectionDetails(details, oxr)
+	default:
+		for _, w := range mergeConnectionDetails(details, observed, cfg.ConnectionDetailsPrecedence) {
+			response.Warning(rsp, w)
+		}
+	}
+
+	deriveMetadataEntries(details, cfg.MetadataEntries, oxr)
+
+	for _, w := range applyBindingKeys(details, cfg.BindingKeys, oxr, observed) {
+		response.Warning(rsp, w)
+	}
+
+	for k, v := range cfg.BindingSecretOverrides {
+		details.set(k, []byte(v))
+	}
+
+	for k, v := range extraKeys {
+		if _, exists := details.data[k]; !exists {
+			details.set(k, []byte(v))
+		}
+	}
+
+	for _, w := range renderBindingTemplates(details, cfg.BindingSecretTemplates, oxr) {
+		response.Warning(rsp, w)
+	}
+
+	if !checkBinding(cfg.Validation, details.data, rsp) {
+		return nil, false
+	}
+
+	secretType, warnings, err := bindingSecretType(cfg, details, observed)
+	if err != nil {
+		response.Warning(rsp, err)
+		return nil, true
+	}
+	for _, w := range warnings {
+		response.Warning(rsp, w)
+	}
+
+	store, published, err := connectionDetailsStore(cfg, oxr)
+	if err != nil {
+		response.Fatal(rsp, errors.Wrap(err, "cannot determine secret store of published connection details"))
+		return nil, false
+	}
+
+	// unless secret data is referenced or read from a secret store, all entries are embedded in the manifest of the binding secret
+	data := details.data
+	var references []providerv1alpha1.Reference
+	var remote *remoteSecretData
+	switch {
+	case store != nil && store.SecretStoreRef != nil:
+		// values the XR publishes to the secret store are read from it by an ExternalSecret
+		data, remote, warnings = publishedRemoteSecretData(details, cfg.BindingSecretTemplates, oxr, store, published)
+		for _, w := range warnings {
+			response.Warning(rsp, w)
+		}
+	case store != nil:
+		// values the XR publishes to the secret store are copied from the published secret by provider-kubernetes
+		ref := &xpv1.SecretReference{Name: published, Namespace: store.Namespace}
+		data, references, warnings = referenceSecretData(details, oxr, observed, ref)
+		for _, w := range warnings {
+			response.Warning(rsp, w)
+		}
+	case composesExternalSecret(cfg):
+		// values read from connection details are read from the secret store instead
+		data = embeddedSecretData(details)
+		remote, warnings, err = externalSecretRemote(cfg, details, oxr, claim)
+		if err != nil {
+			response.Fatal(rsp, errors.Wrap(err, "cannot compose ExternalSecret"))
+			return nil, false
+		}
+		for _, w := range warnings {
+			response.Warning(rsp, w)
+		}
+	case cfg.SecretDataMode != v1alpha1.SecretDataModeReferenced:
+	case composesDirectly(cfg):
+		response.Warning(rsp, errors.New("cannot reference secret data of a directly composed binding secret, embedding all values instead"))
+	default:
+		data, references, warnings = referenceSecretData(details, oxr, observed, nil)
+		for _, w := range warnings {
+			response.Warning(rsp, w)
+		}
+	}
+
+	// the claim didn't specify a secret to write the connection details to
+	// but we also don't require it to do so, rather it's up to us to create a secret now
+	// we can't do this by setting spec.writeConnectionSecretToRef on the XR though as we are
+	// only allowed to mutate the XR's status, not its spec
+	// so instead we compose a new secret and created it using provider-kubernetes
+	// the same applies if the claim's secret is to be enriched, the binding secret must not replace the claim's secret
+	secretName, err := bindingSecretName(cfg.SecretName, oxr, claim)
+	if err != nil {
+		response.Fatal(rsp, errors.Wrap(err, "cannot determine name of binding secret"))
+		return nil, false
+	}
+
+	if current := observedSecretName(cfg, observed); current != "" && current != secretName {
+		response.Warning(rsp, errors.Errorf("cannot rename binding secret from %q to %q after it has been created, the provider-kubernetes Object managing it is named after it", current, secretName))
+		secretName = current
+	}
+
+	if connSecretRef != nil && secretName == connSecretRef.Name {
+		response.Fatal(rsp, errors.Errorf("cannot determine name of binding secret, %q is the name of the claim's connection secret", secretName))
+		return nil, false
+	}
+
+	labels, annotations, warnings := bindingSecretMetadata(cfg, details.data, oxr, claim)
+	for _, w := range warnings {
+		response.Warning(rsp, w)
+	}
+
+	if !composesDirectly(cfg) {
+		dependencies, warnings := bindingDependencies(cfg, claim.Namespace, oxr, claim, desiredComposed)
+		for _, w := range warnings {
+			response.Warning(rsp, w)
+		}
+		references = append(references, dependencies...)
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        secretName,
+			Namespace:   claim.Namespace,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Data: data,
+		Type: secretType,
+	}
+
+	resourceName := bindingSecretResourceName(cfg)
+	if _, exists := desiredComposed[resourceName]; exists {
+		response.Fatal(rsp, errors.Errorf("cannot compose binding secret, an earlier step of the pipeline already composes resource %q", resourceName))
+		return nil, false
+	}
+
+	dcd, err := composeSecretResource(cfg, oxr, secret, references, remote, observed)
+	if err != nil {
+		response.Fatal(rsp, errors.Wrap(err, "cannot compose binding secret"))
+		return nil, false
+	}
+
+	desiredComposed[resourceName] = dcd
+
+	return &composedBinding{secretName: secret.Name, data: details.data}, true
+}
+
+// composeSecretResource returns the desired composed resource for the supplied binding secret
+// this is either the ExternalSecret creating it, if values are read from the supplied remote data,
+// the secret itself or the provider-kubernetes Object managing it
+func composeSecretResource(cfg v1alpha1.Config, oxr *resource.Composite, secret *corev1.Secret, references []providerv1alpha1.Reference, remote *remoteSecretData, observed map[resource.Name]resource.ObservedComposed) (*resource.DesiredComposed, error) {
+	if remote != nil {
+		cd, err := externalSecretObject(cfg, secret, remote)
+		if err != nil {
+			return nil, err
+		}
+		return &resource.DesiredComposed{Resource: cd}, nil
+	}
+
+	if composesDirectly(cfg) {
+		cd, err := composed.From(secret)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get composed resource from %T", secret)
+		}
+
+		// secrets do not report any conditions, they are ready as soon as they exist
+		return &resource.DesiredComposed{Resource: cd, Ready: resource.ReadyTrue}, nil
+	}
+
+	enc := scheme.Codecs.EncoderForVersion(&json.Serializer{}, corev1.SchemeGroupVersion)
+	buffer := &bytes.Buffer{}
+	if err := enc.Encode(secret, buffer); err != nil {
+		return nil, errors.Wrapf(err, "cannot encode secret %T", secret)
+	}
+
+	// the provider-kubernetes object for the secret
+	cd, err := composeObject(cfg, oxr, buffer.Bytes(), references, secret.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	cd.SetLabels(secret.GetLabels())
+	cd.SetAnnotations(secret.GetAnnotations())
+
+	if namesObjectAfterSecret(cfg) {
+		name := bindingObjectName(secret.Namespace, secret.Name)
+		if cd.GetNamespace() != "" {
+			// namespaced Objects can only collide with Objects in the same namespace
+			name = secret.Name
+		}
+		// the observed Object manages the observed secret, which keeps its name, see observedSecretName
+		if ocr, ok := observed[bindingSecretResourceName(cfg)]; ok && ocr.Resource.GetName() != "" {
+			name = ocr.Resource.GetName()
+		}
+		cd.SetName(name)
+	}
+
+	return &resource.DesiredComposed{Resource: cd}, nil
+}
+
+// composesDirectly returns whether the binding secret, or the ExternalSecret creating it, and the ServiceBinding
+// are composed directly rather than as manifests of provider-kubernetes Objects
+func composesDirectly(cfg v1alpha1.Config) bool {
+	return cfg.SecretComposition == v1alpha1.SecretCompositionSecret || composesExternalSecret(cfg)
+}
@@ -0,0 +1,464 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/function-sdk-go/resource"
+
+	"github.com/st3v/servicebinding-decorator/input/v1alpha1"
+)
+
+// connectionDetailRef refers to a connection detail of an observed composed resource
+// an empty resource name refers to a connection detail of the observed XR
+type connectionDetailRef struct {
+	resource resource.Name
+	key      string
+}
+
+// bindingDetails holds the entries of a binding
+// for entries read from a connection detail, it also keeps track of the connection detail they were read from
+// and for entries rendered from templates, whether the template read values of connection details
+type bindingDetails struct {
+	data      map[string][]byte
+	sources   map[string]connectionDetailRef
+	sensitive map[string]bool
+}
+
+func newBindingDetails() *bindingDetails {
+	return &bindingDetails{
+		data:      map[string][]byte{},
+		sources:   map[string]connectionDetailRef{},
+		sensitive: map[string]bool{},
+	}
+}
+
+// set sets an entry whose value was not read from a connection detail
+func (b *bindingDetails) set(key string, value []byte) {
+	b.data[key] = value
+	delete(b.sources, key)
+	delete(b.sensitive, key)
+}
+
+// setFrom sets an entry whose value was read from the supplied connection detail
+func (b *bindingDetails) setFrom(key string, value []byte, src connectionDetailRef) {
+	b.data[key] = value
+	b.sources[key] = src
+	delete(b.sensitive, key)
+}
+
+// setSensitive sets an entry whose value was rendered from a template reading values of connection details
+func (b *bindingDetails) setSensitive(key string, value []byte) {
+	b.set(key, value)
+	b.sensitive[key] = true
+}
+
+// mergeConnectionDetails merges the connection details of the supplied observed composed resources into details
+// resources listed in precedence win over all other resources, the remaining resources are merged
+// in alphabetical order of their names so that the result is stable across reconciles
+// for every key that is published with different values by more than one resource a warning is returned
+func mergeConnectionDetails(details *bindingDetails, observed map[resource.Name]resource.ObservedComposed, precedence []string) []error {
+	merged := newBindingDetails()
+	warnings := []error{}
+
+	for _, name := range precedenceOrder(observed, precedence) {
+		cd := observed[name].ConnectionDetails
+
+		keys := make([]string, 0, len(cd))
+		for k := range cd {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			src, exists := merged.sources[k]
+			if !exists {
+				merged.setFrom(k, cd[k], connectionDetailRef{resource: name, key: k})
+				continue
+			}
+
+			if !bytes.Equal(merged.data[k], cd[k]) {
+				warnings = append(warnings, errors.Errorf("connection detail %q is published by composed resources %q and %q, using the value of %q", k, src.resource, name, src.resource))
+			}
+		}
+	}
+
+	for k, v := range merged.data {
+		details.setFrom(k, v, merged.sources[k])
+	}
+
+	return warnings
+}
+
+// mergeCompositeConnectionDetails merges the connection details of the supplied XR into details
+// these are the entries of the XR's connection secret
+func mergeCompositeConnectionDetails(details *bindingDetails, xr *resource.Composite) {
+	for k, v := range xr.ConnectionDetails {
+		details.setFrom(k, v, connectionDetailRef{key: k})
+	}
+}
+
+// deriveMetadataEntries sets the supplied binding entries derived from the metadata of the XR on details
+// entries maps binding entries to the annotation or label keys they are derived from, values are read from the XR's
+// annotations, its labels or the labels of its composition selector, entries whose key cannot be found are skipped
+func deriveMetadataEntries(details *bindingDetails, entries map[string]string, xr *resource.Composite) {
+	if len(entries) == 0 {
+		return
+	}
+
+	// the XR might not select its composition by labels, in which case only its own metadata is considered
+	selector, _ := xr.Resource.GetStringObject("spec.compositionSelector.matchLabels")
+	sources := []map[string]string{
+		xr.Resource.GetAnnotations(),
+		xr.Resource.GetLabels(),
+		selector,
+	}
+
+	for entry, key := range entries {
+		for _, src := range sources {
+			if v, ok := src[key]; ok && v != "" {
+				details.set(entry, []byte(v))
+				break
+			}
+		}
+	}
+}
+
+// precedenceOrder returns the names of the observed composed resources ordered from highest to lowest precedence
+// names in precedence that do not refer to an observed composed resource are skipped
+func precedenceOrder(observed map[resource.Name]resource.ObservedComposed, precedence []string) []resource.Name {
+	ordered := make([]resource.Name, 0, len(observed))
+	listed := map[resource.Name]bool{}
+
+	for _, n := range precedence {
+		name := resource.Name(n)
+		if _, ok := observed[name]; !ok || listed[name] {
+			continue
+		}
+		ordered = append(ordered, name)
+		listed[name] = true
+	}
+
+	rest := make([]resource.Name, 0, len(observed))
+	for name := range observed {
+		if !listed[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i] < rest[j] })
+
+	return append(ordered, rest...)
+}
+
+// applyBindingKeys sets the binding entries specified by keys on details
+// for every entry whose source cannot be found a warning is returned and the entry is skipped
+func applyBindingKeys(details *bindingDetails, keys []v1alpha1.BindingKey, xr *resource.Composite, observed map[resource.Name]resource.ObservedComposed) []error {
+	warnings := []error{}
+
+	for _, key := range keys {
+		if err := applyBindingKey(details, key, xr, observed); err != nil {
+			warnings = append(warnings, errors.Wrapf(err, "cannot read value for binding key %q", key.Name))
+		}
+	}
+
+	return warnings
+}
+
+// applyBindingKey reads the value of the supplied binding key from its source and sets it on details
+func applyBindingKey(details *bindingDetails, key v1alpha1.BindingKey, xr *resource.Composite, observed map[resource.Name]resource.ObservedComposed) error {
+	switch key.Type {
+	case v1alpha1.BindingKeySourceFromCompositeFieldPath:
+		if key.FieldPath == "" {
+			return errors.Errorf("fieldPath must be specified for binding keys of type %s", key.Type)
+		}
+
+		v, err := xr.Resource.GetValue(key.FieldPath)
+		if err != nil {
+			return errors.Wrapf(err, "cannot get field %q of composite resource", key.FieldPath)
+		}
+		if v == nil {
+			return errors.Errorf("cannot get field %q of composite resource: field is null", key.FieldPath)
+		}
+		return setFieldValue(details, key.Name, v)
+
+	case v1alpha1.BindingKeySourceFromComposedFieldPath:
+		if key.FieldPath == "" {
+			return errors.Errorf("fieldPath must be specified for binding keys of type %s", key.Type)
+		}
+
+		ocr, ok := observed[resource.Name(key.ResourceName)]
+		if !ok {
+			return errors.Errorf("cannot find composed resource %q", key.ResourceName)
+		}
+
+		v, err := ocr.Resource.GetValue(key.FieldPath)
+		if err != nil {
+			return errors.Wrapf(err, "cannot get field %q of composed resource %q", key.FieldPath, key.ResourceName)
+		}
+		if v == nil {
+			return errors.Errorf("cannot get field %q of composed resource %q: field is null", key.FieldPath, key.ResourceName)
+		}
+		return setFieldValue(details, key.Name, v)
+
+	case "", v1alpha1.BindingKeySourceFromConnectionDetail:
+		ocr, ok := observed[resource.Name(key.ResourceName)]
+		if !ok {
+			return errors.Errorf("cannot find composed resource %q", key.ResourceName)
+		}
+
+		connectionDetailKey := key.ConnectionDetailKey
+		if connectionDetailKey == "" {
+			connectionDetailKey = key.Name
+		}
+
+		v, ok := ocr.ConnectionDetails[connectionDetailKey]
+		if !ok {
+			return errors.Errorf("cannot find connection detail %q of composed resource %q", connectionDetailKey, key.ResourceName)
+		}
+
+		details.setFrom(key.Name, v, connectionDetailRef{resource: resource.Name(key.ResourceName), key: connectionDetailKey})
+		return nil
+	}
+
+	return errors.Errorf("unknown binding key source type %q", key.Type)
+}
+
+// setFieldValue converts the value of a field to a binding value and sets it on details
+// strings are used as-is, objects and arrays are encoded as JSON, all other values are formatted
+func setFieldValue(details *bindingDetails, key string, v any) error {
+	switch t := v.(type) {
+	case string:
+		details.set(key, []byte(t))
+	case map[string]any, []any:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return errors.Wrap(err, "cannot encode field value")
+		}
+		details.set(key, b)
+	default:
+		details.set(key, []byte(fmt.Sprint(v)))
+	}
+	return nil
+}
+
+// renderBindingTemplates renders the supplied templates and sets the results on details
+// templates are rendered against details and the metadata, spec and status of the supplied XR
+// for every template that cannot be rendered a warning is returned and the entry is skipped
+func renderBindingTemplates(details *bindingDetails, templates map[string]string, xr *resource.Composite) []error {
+	warnings := []error{}
+	if len(templates) == 0 {
+		return warnings
+	}
+
+	data := make(map[string]any, len(details.data)+1)
+	for k, v := range details.data {
+		data[k] = string(v)
+	}
+
+	data["xr"] = xrTemplateData(xr)
+
+	keys := make([]string, 0, len(templates))
+	for k := range templates {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	rendered := make(map[string][]byte, len(templates))
+	sensitive := map[string]bool{}
+	for _, k := range keys {
+		tmpl, err := template.New(k).Option("missingkey=error").Parse(templates[k])
+		if err != nil {
+			warnings = append(warnings, errors.Wrapf(err, "cannot parse template for binding key %q", k))
+			continue
+		}
+
+		out := &strings.Builder{}
+		if err := tmpl.Execute(out, data); err != nil {
+			warnings = append(warnings, errors.Wrapf(err, "cannot render template for binding key %q", k))
+			continue
+		}
+
+		rendered[k] = []byte(out.String())
+		sensitive[k] = readsConnectionDetails(tmpl, details.sources)
+	}
+
+	// templates are rendered against the same data, independent of the order they are rendered in
+	for k, v := range rendered {
+		if sensitive[k] {
+			details.setSensitive(k, v)
+			continue
+		}
+		details.set(k, v)
+	}
+
+	return warnings
+}
+
+// readsConnectionDetails returns whether the supplied template reads values of the supplied connection details
+// this is decided by the keys the template refers to rather than by its output, any use of such a value, e.g. printing
+// a prefix of it or comparing it, counts, so does any use of the template's data as a whole, e.g. {{ . }}
+func readsConnectionDetails(tmpl *template.Template, sources map[string]connectionDetailRef) bool {
+	r := &templateReads{tmpl: tmpl, sources: sources, walked: map[string]bool{}}
+	return r.list(tmpl.Tree.Root, true)
+}
+
+// templateReads walks the parse tree of a template looking for reads of connection details
+// root specifies whether dot is the template's data, within with and range actions it is a value of the data instead
+// values the template refers to other than through dot or $ can only be derived from values it read before, hence the
+// walk stops at the first read of a connection detail rather than tracking variables
+type templateReads struct {
+	tmpl    *template.Template
+	sources map[string]connectionDetailRef
+	walked  map[string]bool
+}
+
+func (r *templateReads) list(l *parse.ListNode, root bool) bool {
+	if l == nil {
+		return false
+	}
+	for _, n := range l.Nodes {
+		if r.node(n, root) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *templateReads) node(n parse.Node, root bool) bool {
+	switch n := n.(type) {
+	case *parse.ActionNode:
+		return r.pipe(n.Pipe, root)
+	case *parse.IfNode:
+		return r.pipe(n.Pipe, root) || r.list(n.List, root) || r.list(n.ElseList, root)
+	case *parse.RangeNode:
+		// the pipeline does not read connection details, hence neither does dot within the range
+		return r.pipe(n.Pipe, root) || r.list(n.List, false) || r.list(n.ElseList, root)
+	case *parse.WithNode:
+		return r.pipe(n.Pipe, root) || r.list(n.List, false) || r.list(n.ElseList, root)
+	case *parse.TemplateNode:
+		return r.template(n, root)
+	}
+	return false
+}
+
+// template walks the template invoked by the supplied node, with the template's data as its dot if it is passed on as is
+func (r *templateReads) template(n *parse.TemplateNode, root bool) bool {
+	invokedRoot := false
+	if n.Pipe != nil {
+		if len(n.Pipe.Decl) == 0 && len(n.Pipe.Cmds) == 1 && len(n.Pipe.Cmds[0].Args) == 1 && r.isRoot(n.Pipe.Cmds[0].Args[0], root) {
+			invokedRoot = true
+		} else if r.pipe(n.Pipe, root) {
+			return true
+		}
+	}
+
+	key := fmt.Sprintf("%s/%t", n.Name, invokedRoot)
+	if r.walked[key] {
+		return false
+	}
+	r.walked[key] = true
+
+	invoked := r.tmpl.Lookup(n.Name)
+	if invoked == nil || invoked.Tree == nil {
+		return false
+	}
+	return r.list(invoked.Tree.Root, invokedRoot)
+}
+
+func (r *templateReads) pipe(p *parse.PipeNode, root bool) bool {
+	if p == nil {
+		return false
+	}
+	for _, c := range p.Cmds {
+		if r.command(c, root) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *templateReads) command(c *parse.CommandNode, root bool) bool {
+	// index of the template's data by a constant key only reads the value of that key
+	if len(c.Args) >= 3 {
+		if fn, ok := c.Args[0].(*parse.IdentifierNode); ok && fn.Ident == "index" && r.isRoot(c.Args[1], root) {
+			key, ok := c.Args[2].(*parse.StringNode)
+			if !ok || r.isSource(key.Text) {
+				return true
+			}
+			for _, a := range c.Args[3:] {
+				if r.arg(a, root) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	for _, a := range c.Args {
+		if r.arg(a, root) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *templateReads) arg(n parse.Node, root bool) bool {
+	switch n := n.(type) {
+	case *parse.DotNode:
+		return root
+	case *parse.FieldNode:
+		return r.fields(n.Ident, root)
+	case *parse.VariableNode:
+		if n.Ident[0] == "$" {
+			return r.fields(n.Ident[1:], true)
+		}
+	case *parse.ChainNode:
+		if r.isRoot(n.Node, root) {
+			return r.fields(n.Field, true)
+		}
+		return r.arg(n.Node, root)
+	case *parse.PipeNode:
+		return r.pipe(n, root)
+	}
+	return false
+}
+
+// fields returns whether the supplied field chain reads a connection detail, an empty chain refers to dot itself
+func (r *templateReads) fields(idents []string, root bool) bool {
+	if !root {
+		return false
+	}
+	return len(idents) == 0 || r.isSource(idents[0])
+}
+
+// isRoot returns whether the supplied node refers to the template's data as a whole
+func (r *templateReads) isRoot(n parse.Node, root bool) bool {
+	switch n := n.(type) {
+	case *parse.DotNode:
+		return root
+	case *parse.VariableNode:
+		return len(n.Ident) == 1 && n.Ident[0] == "$"
+	}
+	return false
+}
+
+func (r *templateReads) isSource(key string) bool {
+	_, ok := r.sources[key]
+	return ok
+}
+
+// xrTemplateData returns the metadata, spec and status of the supplied XR for use in templates
+func xrTemplateData(xr *resource.Composite) map[string]any {
+	content := xr.Resource.UnstructuredContent()
+	return map[string]any{
+		"metadata": content["metadata"],
+		"spec":     content["spec"],
+		"status":   content["status"],
+	}
+}
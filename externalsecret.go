@@ -0,0 +1,221 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
+	"github.com/crossplane/function-sdk-go/resource"
+	"github.com/crossplane/function-sdk-go/resource/composed"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/st3v/servicebinding-decorator/input/v1alpha1"
+)
+
+const (
+	// externalSecretAPIVersion is the apiVersion of composed ExternalSecrets
+	externalSecretAPIVersion = "external-secrets.io/v1beta1"
+
+	// externalSecretKind is the kind of composed ExternalSecrets
+	externalSecretKind = "ExternalSecret"
+)
+
+// remoteSecretData specifies where an ExternalSecret reads the binding's values read from connection details from
+type remoteSecretData struct {
+	// store is the SecretStore the values are read from
+	store v1alpha1.SecretStoreRef
+
+	// key is the remote key the values are read from
+	key string
+
+	// properties maps binding keys to the properties of the remote key they are read from
+	properties map[string]string
+
+	// templates maps binding keys to the templates the ExternalSecret renders them from, these templates read the values
+	// read from the remote key, e.g. connection URIs containing passwords
+	templates map[string]string
+}
+
+// composesExternalSecret returns whether an ExternalSecret is composed for the binding secret
+func composesExternalSecret(cfg v1alpha1.Config) bool {
+	return cfg.SecretComposition == v1alpha1.SecretCompositionExternalSecret
+}
+
+// embeddedSecretData returns the entries of the binding that are not read from connection details
+// entries read from connection details are credentials, an ExternalSecret reads these from the secret store instead,
+// entries rendered from templates reading values of connection details are rendered by the ExternalSecret
+func embeddedSecretData(details *bindingDetails) map[string][]byte {
+	data := map[string][]byte{}
+	for k, v := range details.data {
+		if _, ok := details.sources[k]; ok || details.sensitive[k] {
+			continue
+		}
+		data[k] = v
+	}
+	return data
+}
+
+// externalSecretRemote returns the configured SecretStore and remote key the values of the binding read from connection details
+// are read from, these are read from the properties named after the connection details they were read from
+// templates of entries reading values of connection details that cannot be rendered by the ExternalSecret are reported as warnings
+func externalSecretRemote(cfg v1alpha1.Config, details *bindingDetails, xr *resource.Composite, c *claim.Reference) (*remoteSecretData, []error, error) {
+	es := cfg.ExternalSecret
+	if es == nil {
+		return nil, nil, errors.New("externalSecret must be specified if secretComposition is ExternalSecret")
+	}
+
+	if es.SecretStoreRef.Name == "" {
+		return nil, nil, errors.New("externalSecret.secretStoreRef.name must be specified")
+	}
+
+	key, err := renderMetadataTemplate("externalSecret.remoteKey", es.RemoteKey, xr, c)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "cannot render remote key template")
+	}
+
+	if key == "" {
+		return nil, nil, errors.New("remote key of ExternalSecret must not be empty")
+	}
+
+	properties := make(map[string]string, len(details.sources))
+	for k, src := range details.sources {
+		properties[k] = src.key
+	}
+
+	templates, warnings := remoteTemplates(details, cfg.BindingSecretTemplates, properties)
+
+	return &remoteSecretData{store: es.SecretStoreRef, key: key, properties: properties, templates: templates}, warnings, nil
+}
+
+// remoteTemplates returns the templates of the entries of the binding rendered from values of connection details
+// an ExternalSecret renders these from the supplied properties it reads rather than their rendered values being embedded,
+// hence a template must render the same value from the entries read from the secret store alone
+// templates that do not are omitted and reported as warnings
+func remoteTemplates(details *bindingDetails, templates map[string]string, properties map[string]string) (map[string]string, []error) {
+	remote := map[string]string{}
+	warnings := []error{}
+
+	data := make(map[string]any, len(properties))
+	for k := range properties {
+		data[k] = string(details.data[k])
+	}
+
+	keys := make([]string, 0, len(details.sensitive))
+	for k := range details.sensitive {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		tmpl, err := template.New(k).Option("missingkey=error").Parse(templates[k])
+		if err != nil {
+			warnings = append(warnings, errors.Wrapf(err, "cannot parse template for binding key %q", k))
+			continue
+		}
+
+		out := &strings.Builder{}
+		if err := tmpl.Execute(out, data); err != nil || out.String() != string(details.data[k]) {
+			warnings = append(warnings, errors.Errorf("cannot render binding key %q from the secret store, its template reads values that are not read from the secret store", k))
+			continue
+		}
+
+		remote[k] = templates[k]
+	}
+
+	return remote, warnings
+}
+
+// externalSecretObject returns the ExternalSecret creating the supplied binding secret as its target
+// the ExternalSecret reads the supplied remote data and merges the binding secret's data into it
+func externalSecretObject(cfg v1alpha1.Config, secret *corev1.Secret, remote *remoteSecretData) (*composed.Unstructured, error) {
+	kind := remote.store.Kind
+	if kind == "" {
+		kind = "SecretStore"
+	}
+
+	tmpl := map[string]any{
+		"metadata": map[string]any{
+			"labels":      stringMap(secret.Labels),
+			"annotations": stringMap(secret.Annotations),
+		},
+		// the entries embedded in the template complement the properties read from the secret store
+		"mergePolicy": "Merge",
+	}
+
+	if secret.Type != "" {
+		tmpl["type"] = string(secret.Type)
+	}
+
+	if len(secret.Data) > 0 || len(remote.templates) > 0 {
+		data := map[string]any{}
+		for k, v := range secret.Data {
+			data[k] = templateLiteral(string(v))
+		}
+		// these are rendered from the values read from the secret store
+		for k, v := range remote.templates {
+			data[k] = v
+		}
+		tmpl["data"] = data
+	}
+
+	spec := map[string]any{
+		"secretStoreRef": map[string]any{
+			"name": remote.store.Name,
+			"kind": kind,
+		},
+		"target": map[string]any{
+			"name":           secret.Name,
+			"creationPolicy": "Owner",
+			"template":       tmpl,
+		},
+	}
+
+	if len(remote.properties) > 0 {
+		data := make([]any, 0, len(remote.properties))
+		for _, k := range sortedKeys(remote.properties) {
+			data = append(data, map[string]any{
+				"secretKey": k,
+				"remoteRef": map[string]any{
+					"key":      remote.key,
+					"property": remote.properties[k],
+				},
+			})
+		}
+		spec["data"] = data
+	}
+
+	if es := cfg.ExternalSecret; es != nil && es.RefreshInterval != "" {
+		spec["refreshInterval"] = es.RefreshInterval
+	}
+
+	object := composed.New()
+	object.SetAPIVersion(externalSecretAPIVersion)
+	object.SetKind(externalSecretKind)
+	object.SetName(secret.Name)
+	object.SetNamespace(secret.Namespace)
+	object.SetLabels(secret.Labels)
+	object.SetAnnotations(secret.Annotations)
+
+	return object, errors.Wrap(object.SetValue("spec", spec), "cannot set spec of ExternalSecret")
+}
+
+// templateLiteral returns the supplied value as a literal of an ExternalSecret template
+// values that would otherwise be evaluated as a template are quoted as a Go template string
+func templateLiteral(v string) string {
+	if !strings.Contains(v, "{{") {
+		return v
+	}
+	return "{{ " + strconv.Quote(v) + " }}"
+}
+
+// stringMap returns the supplied map as a map of arbitrary values
+func stringMap(m map[string]string) map[string]any {
+	out := make(map[string]any, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
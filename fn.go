@@ -1,8 +1,10 @@
 package main
 
 import (
-	"bytes"
 	"context"
+	"fmt"
+	"reflect"
+	"strings"
 
 	providerv1alpha1 "github.com/crossplane-contrib/provider-kubernetes/apis/object/v1alpha1"
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
@@ -10,14 +12,9 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/logging"
 	fnv1beta1 "github.com/crossplane/function-sdk-go/proto/v1beta1"
 	"github.com/crossplane/function-sdk-go/request"
-	"github.com/crossplane/function-sdk-go/resource"
 	"github.com/crossplane/function-sdk-go/resource/composed"
 	"github.com/crossplane/function-sdk-go/response"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/runtime/serializer/json"
-	"k8s.io/client-go/kubernetes/scheme"
 
 	"github.com/st3v/servicebinding-decorator/input/v1alpha1"
 )
@@ -33,6 +30,11 @@ func init() {
 	if err := providerv1alpha1.SchemeBuilder.AddToScheme(composed.Scheme); err != nil {
 		panic(err)
 	}
+
+	// binding secrets might be composed directly
+	if err := corev1.AddToScheme(composed.Scheme); err != nil {
+		panic(err)
+	}
 }
 
 // RunFunction runs the Function.
@@ -65,24 +67,31 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1beta1.RunFunctionRequ
 
 	claim := oxr.Resource.GetClaimReference()
 	if claim == nil {
-		response.Normal(rsp, "claim reference is nil, nothing to do")
-		return rsp, nil
-	}
+		// without a claim, the XR itself might be bound into its own namespace or a target namespace
+		ref, err := compositeReference(decorator.Config.TargetNamespace, oxr)
+		if err != nil {
+			response.Fatal(rsp, errors.Wrap(err, "cannot determine target namespace"))
+			return rsp, nil
+		}
 
-	connSecretRef := oxr.Resource.GetWriteConnectionSecretToReference()
-	if connSecretRef != nil && connSecretRef.Namespace == claim.Namespace {
-		// looks like the claim specified a secret to write the connection details to
-		// and that secret is in the same namespace as the claim
-		// we can just refer to that secret
-		return setStatusBindingName(connSecretRef.Name, req, rsp), nil
+		if ref == nil {
+			response.Normal(rsp, "claim reference is nil, nothing to do")
+			return rsp, nil
+		}
+
+		claim = ref
 	}
 
-	// do we require the claim to specify a secret to write the connection details to?
-	if decorator.Config.RequireWriteConnectionSecretToRef {
-		// note, we do not treat this as an error, the claim is simply not bindable in this case
-		response.Normal(rsp, "claim does not specify spec.writeConnectionSecretToRef, nothing to do")
+	cfg, extraKeys, skip, warnings := applyClaimOverrides(decorator.Config, oxr)
+	for _, w := range warnings {
+		response.Warning(rsp, w)
+	}
+	if skip {
+		response.Normalf(rsp, "claim skips its binding using annotation %q, nothing to do", annotationBindingSkip)
 		return rsp, nil
 	}
+	configured := decorator.Config
+	decorator.Config = cfg
 
 	observed, err := request.GetObservedComposedResources(req)
 	if err != nil {
@@ -90,56 +99,81 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1beta1.RunFunctionRequ
 		return rsp, nil
 	}
 
-	connectionDetails := map[string][]byte{}
-	for _, ocr := range observed {
-		for k, v := range ocr.ConnectionDetails {
-			connectionDetails[k] = v
+	connSecretRef := oxr.Resource.GetWriteConnectionSecretToReference()
+	claimConnSecret := connSecretRef != nil && connSecretRef.Namespace == claim.Namespace
+	if claimConnSecret && decorator.Config.ClaimConnectionSecretMode != v1alpha1.ClaimConnectionSecretModeEnrich {
+		// looks like the claim specified a secret to write the connection details to
+		// and that secret is in the same namespace as the claim
+		// we can just refer to that secret
+		ignored := bindingSecretSettings(configured)
+		if !reflect.DeepEqual(configured.SecretName, decorator.Config.SecretName) {
+			ignored = append(ignored, fmt.Sprintf("annotation %q", annotationBindingName))
+		}
+		if len(extraKeys) > 0 {
+			ignored = append(ignored, fmt.Sprintf("annotation %q", annotationBindingExtraKeys))
+		}
+		if len(ignored) > 0 {
+			response.Warning(rsp, errors.Errorf("claim's connection secret is used as the binding secret as is, ignoring %s, set claimConnectionSecretMode to Enrich to apply them", strings.Join(ignored, ", ")))
 		}
-	}
 
-	for k, v := range decorator.Config.BindingSecretOverrides {
-		connectionDetails[k] = []byte(v)
-	}
+		// the claim's connection secret is validated as the binding secret would be
+		if !checkBinding(decorator.Config.Validation, oxr.ConnectionDetails, rsp) {
+			return rsp, nil
+		}
 
-	// the claim didn't specify a secret to write the connection details to
-	// but we also don't require it to do so, rather it's up to us to create a secret now
-	// we can't do this by setting spec.writeConnectionSecretToRef on the XR though as we are
-	// only allowed to mutate the XR's status, not its spec
-	// so instead we compose a new secret and created it using provider-kubernetes
-	secret := corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      string(oxr.Resource.GetUID()),
-			Namespace: claim.Namespace,
-		},
-		Data: connectionDetails,
-	}
+		if decorator.Config.ServiceBinding != nil {
+			desiredComposed, err := request.GetDesiredComposedResources(req)
+			if err != nil {
+				response.Fatal(rsp, errors.Wrapf(err, "cannot get desired composed resources from %T", req))
+				return rsp, nil
+			}
+
+			if !composeServiceBinding(decorator.Config, oxr, claim, desiredComposed, rsp) {
+				return rsp, nil
+			}
 
-	providerConfigName := "default"
-	if decorator.Config.ProviderConfigRef != nil && decorator.Config.ProviderConfigRef.Name != "" {
-		providerConfigName = decorator.Config.ProviderConfigRef.Name
+			if err := response.SetDesiredComposedResources(rsp, desiredComposed); err != nil {
+				response.Fatal(rsp, errors.Wrapf(err, "cannot set desired composed resources in %T", rsp))
+				return rsp, nil
+			}
+		}
+
+		// the binding secret is the connection secret, its entries are the connection details of the XR
+		return publishBinding(decorator.Config, connSecretRef.Name, oxr.ConnectionDetails, nil, oxr, observed, req, rsp), nil
 	}
 
-	enc := scheme.Codecs.EncoderForVersion(&json.Serializer{}, corev1.SchemeGroupVersion)
-	buffer := &bytes.Buffer{}
-	if err := enc.Encode(&secret, buffer); err != nil {
-		response.Fatal(rsp, errors.Wrapf(err, "cannot encode secret %T", secret))
-		return rsp, nil
+	if importsConnectionSecret(decorator.Config, connSecretRef, claim) {
+		// the connection secret is in another namespace, secretgen-controller shares it with the claim's namespace
+		desiredComposed, err := request.GetDesiredComposedResources(req)
+		if err != nil {
+			response.Fatal(rsp, errors.Wrapf(err, "cannot get desired composed resources from %T", req))
+			return rsp, nil
+		}
+
+		if !composeSecretImport(decorator.Config, oxr, claim, connSecretRef, desiredComposed, rsp) {
+			return rsp, nil
+		}
+
+		if decorator.Config.ServiceBinding != nil {
+			if !composeServiceBinding(decorator.Config, oxr, claim, desiredComposed, rsp) {
+				return rsp, nil
+			}
+		}
+
+		if err := response.SetDesiredComposedResources(rsp, desiredComposed); err != nil {
+			response.Fatal(rsp, errors.Wrapf(err, "cannot set desired composed resources in %T", rsp))
+			return rsp, nil
+		}
+
+		// the binding secret is the connection secret, its entries are the connection details of the XR
+		return publishBinding(decorator.Config, connSecretRef.Name, oxr.ConnectionDetails, nil, oxr, observed, req, rsp), nil
 	}
 
-	// the provider-kubernetes object for the secret
-	object := providerv1alpha1.Object{
-		Spec: providerv1alpha1.ObjectSpec{
-			ForProvider: providerv1alpha1.ObjectParameters{
-				Manifest: runtime.RawExtension{
-					Raw: buffer.Bytes(),
-				},
-			},
-			ResourceSpec: providerv1alpha1.ResourceSpec{
-				ProviderConfigReference: &xpv1.Reference{
-					Name: providerConfigName,
-				},
-			},
-		},
+	// do we require the claim to specify a secret to write the connection details to?
+	if !claimConnSecret && decorator.Config.RequireWriteConnectionSecretToRef {
+		// note, we do not treat this as an error, the claim is simply not bindable in this case
+		response.Normal(rsp, "claim does not specify spec.writeConnectionSecretToRef, nothing to do")
+		return rsp, nil
 	}
 
 	desiredComposed, err := request.GetDesiredComposedResources(req)
@@ -148,39 +182,158 @@ func (f *Function) RunFunction(_ context.Context, req *fnv1beta1.RunFunctionRequ
 		return rsp, nil
 	}
 
-	composed, err := composed.From(&object)
+	// the claim's connection secret is only passed on if it is to be enriched
+	if !claimConnSecret {
+		connSecretRef = nil
+	}
+
+	bindings, err := bindingConfigs(decorator.Config, extraKeys)
 	if err != nil {
-		response.Fatal(rsp, errors.Wrapf(err, "cannot get composed resource from %T", object))
+		response.Fatal(rsp, errors.Wrap(err, "invalid bindings"))
 		return rsp, nil
 	}
 
-	desiredComposed["bindingsecret"] = &resource.DesiredComposed{Resource: composed}
+	composedBindings := []composedBinding{}
+	var primary *composedBinding
+	for _, b := range bindings {
+		cb, ok := composeNamedBindingSecret(b, oxr, claim, connSecretRef, observed, desiredComposed, rsp)
+		if !ok {
+			return rsp, nil
+		}
+
+		if cb == nil {
+			continue
+		}
+
+		for _, other := range composedBindings {
+			if other.secretName == cb.secretName {
+				response.Fatal(rsp, errors.Errorf("bindings %q and %q cannot both compose binding secret %q", other.name, cb.name, cb.secretName))
+				return rsp, nil
+			}
+		}
+
+		composedBindings = append(composedBindings, *cb)
+		if b.primary {
+			primary = cb
+		}
+	}
+
+	if decorator.Config.ServiceBinding != nil {
+		if !composeServiceBinding(decorator.Config, oxr, claim, desiredComposed, rsp) {
+			return rsp, nil
+		}
+	}
 
 	if err := response.SetDesiredComposedResources(rsp, desiredComposed); err != nil {
 		response.Fatal(rsp, errors.Wrapf(err, "cannot set desired composed resources in %T", rsp))
 		return rsp, nil
 	}
 
-	return setStatusBindingName(secret.Name, req, rsp), nil
+	// status.bindings is only published if a list of bindings is configured
+	if len(decorator.Config.Bindings) == 0 {
+		composedBindings = nil
+	}
+
+	secretName := ""
+	var data map[string][]byte
+	if primary != nil {
+		secretName, data = primary.secretName, primary.data
+	}
+
+	return publishBinding(decorator.Config, secretName, data, composedBindings, oxr, observed, req, rsp), nil
 }
 
-// setStatusBindingName attempts to set status.binding.name on the desired composite in the response
+// setStatusBinding attempts to set status.binding, status.bindings and the supplied conditions on the desired composite in the response
+// status.binding.name is not set if secretName is empty, status.binding.serviceBinding reflects the observed ServiceBinding, if any
+// status.bindings lists the supplied bindings and is not set if there are none
 // if this fails, the function adds a fatal result to the response
-func setStatusBindingName(secretName string, req *fnv1beta1.RunFunctionRequest, rsp *fnv1beta1.RunFunctionResponse) *fnv1beta1.RunFunctionResponse {
+func setStatusBinding(cfg v1alpha1.Config, secretName string, bindings []composedBinding, req *fnv1beta1.RunFunctionRequest, rsp *fnv1beta1.RunFunctionResponse, conditions ...xpv1.Condition) *fnv1beta1.RunFunctionResponse {
 	desiredComposite, err := request.GetDesiredCompositeResource(req)
 	if err != nil {
 		response.Fatal(rsp, errors.Wrapf(err, "cannot get desired composite resource from %T", req))
 		return rsp
 	}
 
-	if err := desiredComposite.Resource.SetString("status.binding.name", secretName); err != nil {
-		response.Fatal(rsp, errors.Wrapf(err, "cannot set desired composite resource in %T", req))
+	if secretName != "" {
+		if err := desiredComposite.Resource.SetString("status.binding.name", secretName); err != nil {
+			response.Fatal(rsp, errors.Wrapf(err, "cannot set desired composite resource in %T", req))
+			return rsp
+		}
+	}
+
+	if len(bindings) > 0 {
+		list := make([]any, 0, len(bindings))
+		for _, b := range bindings {
+			list = append(list, map[string]any{"name": b.name, "secretName": b.secretName})
+
+			if b.statusFieldPath == "" {
+				continue
+			}
+			if err := desiredComposite.Resource.SetString(b.statusFieldPath, b.secretName); err != nil {
+				response.Fatal(rsp, errors.Wrapf(err, "cannot set field %q of desired composite resource in %T", b.statusFieldPath, req))
+				return rsp
+			}
+		}
+
+		if err := desiredComposite.Resource.SetValue("status.bindings", list); err != nil {
+			response.Fatal(rsp, errors.Wrapf(err, "cannot set desired composite resource in %T", req))
+			return rsp
+		}
+	}
+
+	observed, err := request.GetObservedComposedResources(req)
+	if err != nil {
+		response.Fatal(rsp, errors.Wrapf(err, "cannot get observed composed resources from %T", req))
 		return rsp
 	}
 
+	sbStatus, err := serviceBindingStatus(cfg, observed)
+	if err != nil {
+		// the binding itself is not affected, the status of the ServiceBinding is merely not reflected
+		response.Warning(rsp, errors.Wrap(err, "cannot reflect status of ServiceBinding"))
+	}
+
+	if sbStatus != nil {
+		if err := desiredComposite.Resource.SetValue("status.binding.serviceBinding", sbStatus); err != nil {
+			response.Fatal(rsp, errors.Wrapf(err, "cannot set desired composite resource in %T", req))
+			return rsp
+		}
+	}
+
+	if len(conditions) > 0 {
+		desiredComposite.Resource.SetConditions(conditions...)
+	}
+
 	if err := response.SetDesiredCompositeResource(rsp, desiredComposite); err != nil {
 		response.Fatal(rsp, errors.Wrapf(err, "cannot set desired composite resource in %T", rsp))
 	}
 
 	return rsp
 }
+
+// bindingSecretSettings returns the configured settings that shape a composed binding secret
+// none of these apply to the claim's connection secret if it is referred to as is
+func bindingSecretSettings(cfg v1alpha1.Config) []string {
+	settings := []string{}
+	add := func(configured bool, name string) {
+		if configured {
+			settings = append(settings, name)
+		}
+	}
+
+	add(len(cfg.BindingSecretOverrides) > 0, "bindingSecretOverrides")
+	add(len(cfg.BindingSecretTemplates) > 0, "bindingSecretTemplates")
+	add(len(cfg.ConnectionDetailsPrecedence) > 0, "connectionDetailsPrecedence")
+	add(cfg.ConnectionDetailsMode == v1alpha1.ConnectionDetailsModeMapped, "connectionDetailsMode")
+	add(len(cfg.BindingKeys) > 0, "bindingKeys")
+	add(len(cfg.MetadataEntries) > 0, "metadataEntries")
+	add(cfg.SecretDataMode == v1alpha1.SecretDataModeReferenced, "secretDataMode")
+	add(cfg.SecretType != "", "secretType")
+	add(cfg.DeriveSecretType, "deriveSecretType")
+	add(cfg.SecretName != nil, "secretName")
+	add(len(cfg.Labels) > 0, "labels")
+	add(len(cfg.Annotations) > 0, "annotations")
+	add(len(cfg.Bindings) > 0, "bindings")
+
+	return settings
+}
@@ -159,30 +159,4884 @@ func TestRunFunction(t *testing.T) {
 								Resource: resource.MustStructJSON(`{
 									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
 									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"servicebinding.io/type":"my-database"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name": "my-uid",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"servicebinding.io/type":"my-database"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"password":"dGhlaXItcGFzc3dvcmQ=",
+													"username":"dGhlaXItdXNlcg==",
+													"type":"bXktZGF0YWJhc2U="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"my-provider-config"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ConnectionDetailsPrecedence": {
+			reason: "Colliding connection details are resolved by precedence and reported as warnings",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ConnectionDetailsPrecedence: []string{"secret"},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								ConnectionDetails: map[string][]byte{
+									"host":     []byte("database-host"),
+									"password": []byte("database-password"),
+								},
+							},
+							"release": {
+								ConnectionDetails: map[string][]byte{
+									"host": []byte("release-host"),
+								},
+							},
+							"secret": {
+								ConnectionDetails: map[string][]byte{
+									"password": []byte("secret-password"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `connection detail "password" is published by composed resources "secret" and "database", using the value of "secret"`,
+						},
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `connection detail "host" is published by composed resources "database" and "release", using the value of "database"`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name": "my-uid",
+													"namespace": "my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"host":"ZGF0YWJhc2UtaG9zdA==",
+													"password":"c2VjcmV0LXBhc3N3b3Jk"
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"MappedConnectionDetails": {
+			reason: "Only declared binding keys are read from composed resources and renamed on the way",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ConnectionDetailsMode: v1alpha1.ConnectionDetailsModeMapped,
+							BindingKeys: []v1alpha1.BindingKey{
+								{Name: "host", ResourceName: "database", ConnectionDetailKey: "endpoint"},
+								{Name: "password", ResourceName: "secret"},
+								{Name: "port", ResourceName: "database"},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								ConnectionDetails: map[string][]byte{
+									"endpoint": []byte("database-host"),
+									"username": []byte("database-user"),
+								},
+							},
+							"secret": {
+								ConnectionDetails: map[string][]byte{
+									"password": []byte("secret-password"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot read value for binding key "port": cannot find connection detail "port" of composed resource "database"`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name": "my-uid",
+													"namespace": "my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"host":"ZGF0YWJhc2UtaG9zdA==",
+													"password":"c2VjcmV0LXBhc3N3b3Jk"
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"RenderBindingTemplates": {
+			reason: "Templated binding entries are rendered against connection details and the observed XR",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							BindingSecretOverrides: map[string]string{
+								"type": "mysql",
+							},
+							BindingSecretTemplates: map[string]string{
+								"uri":      "{{.type}}://{{.username}}:{{.password}}@{{.host}}/{{.xr.metadata.name}}",
+								"jdbc-url": "jdbc:{{.type}}://{{.host}}:{{.port}}/{{.xr.metadata.name}}",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"name":"my-xr",
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								ConnectionDetails: map[string][]byte{
+									"host":     []byte("my-host"),
+									"username": []byte("my-user"),
+									"password": []byte("my-password"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot render template for binding key "jdbc-url": template: jdbc-url:1:29: executing "jdbc-url" at <.port>: map has no entry for key "port"`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"crossplane.io/composite":"my-xr",
+											"servicebinding.io/type":"mysql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name": "my-uid",
+													"namespace": "my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"crossplane.io/composite":"my-xr",
+														"servicebinding.io/type":"mysql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"host":"bXktaG9zdA==",
+													"password":"bXktcGFzc3dvcmQ=",
+													"type":"bXlzcWw=",
+													"uri":"bXlzcWw6Ly9teS11c2VyOm15LXBhc3N3b3JkQG15LWhvc3QvbXkteHI=",
+													"username":"bXktdXNlcg=="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"FieldPathBindingKeys": {
+			reason: "Binding entries can be read from fields of the observed XR and composed resources",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ConnectionDetailsMode: v1alpha1.ConnectionDetailsModeMapped,
+							BindingKeys: []v1alpha1.BindingKey{
+								{Name: "region", Type: v1alpha1.BindingKeySourceFromCompositeFieldPath, FieldPath: "spec.parameters.region"},
+								{Name: "host", Type: v1alpha1.BindingKeySourceFromComposedFieldPath, ResourceName: "database", FieldPath: "status.atProvider.endpoint"},
+								{Name: "port", Type: v1alpha1.BindingKeySourceFromComposedFieldPath, ResourceName: "database", FieldPath: "status.atProvider.port"},
+								{Name: "zone", Type: v1alpha1.BindingKeySourceFromCompositeFieldPath, FieldPath: "spec.parameters.zone"},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"parameters":{
+										"region":"my-region"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"example.org/v1",
+									"kind":"Database",
+									"status":{
+										"atProvider":{
+											"endpoint":"my-host",
+											"port":3306
+										}
+									}
+								}`),
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot read value for binding key "zone": cannot get field "spec.parameters.zone" of composite resource: spec.parameters.zone: no such field`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name": "my-uid",
+													"namespace": "my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"host":"bXktaG9zdA==",
+													"port":"MzMwNg==",
+													"region":"bXktcmVnaW9u"
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ReferenceSecretData": {
+			reason: "Values read from connection details are referenced from connection secrets rather than embedded, neither are templates reading them",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							SecretDataMode: v1alpha1.SecretDataModeReferenced,
+							BindingSecretOverrides: map[string]string{
+								"type": "mysql",
+							},
+							BindingSecretTemplates: map[string]string{
+								"name":    "{{.type}}-database",
+								"uri":     "mysql://root:{{.password}}@{{.host}}",
+								"hint":    `{{printf "%.6s" .password}}`,
+								"initial": `{{if eq (slice (index . "password") 0 1) "m"}}m{{end}}`,
+								"engine":  `{{index . "type"}}`,
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"writeConnectionSecretToRef":{
+										"name":"xr-secret",
+										"namespace":"crossplane-system"
+									}
+								}
+							}`),
+							ConnectionDetails: map[string][]byte{
+								"host": []byte("my-host"),
+							},
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"example.org/v1",
+									"kind":"Database",
+									"spec":{
+										"writeConnectionSecretToRef":{
+											"name":"database-secret",
+											"namespace":"crossplane-system"
+										}
+									}
+								}`),
+								ConnectionDetails: map[string][]byte{
+									"password": []byte("my-password"),
+								},
+							},
+							"release": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"example.org/v1",
+									"kind":"Release"
+								}`),
+								ConnectionDetails: map[string][]byte{
+									"host": []byte("my-host"),
+									"port": []byte("3306"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot embed binding key "hint", its template reads values of connection details which must not be embedded in the binding secret's manifest`,
+						},
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot embed binding key "initial", its template reads values of connection details which must not be embedded in the binding secret's manifest`,
+						},
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot reference connection detail "port" of composed resource "release" for binding key "port", neither the composed resource nor the composite resource write it to a connection secret`,
+						},
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot embed binding key "uri", its template reads values of connection details which must not be embedded in the binding secret's manifest`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"servicebinding.io/type":"mysql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name": "my-uid",
+													"namespace": "my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"servicebinding.io/type":"mysql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"engine":"bXlzcWw=",
+													"name":"bXlzcWwtZGF0YWJhc2U=",
+													"type":"bXlzcWw="
+												}
+											}
+										},
+										"references":[
+											{
+												"patchesFrom":{
+													"apiVersion":"v1",
+													"kind":"Secret",
+													"name":"xr-secret",
+													"namespace":"crossplane-system",
+													"fieldPath":"data.host"
+												},
+												"toFieldPath":"data.host"
+											},
+											{
+												"patchesFrom":{
+													"apiVersion":"v1",
+													"kind":"Secret",
+													"name":"database-secret",
+													"namespace":"crossplane-system",
+													"fieldPath":"data.password"
+												},
+												"toFieldPath":"data.password"
+											}
+										],
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"DeriveSecretType": {
+			reason: "Secret type is derived from the binding's type entry",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							DeriveSecretType: true,
+							BindingSecretOverrides: map[string]string{
+								"type": "mysql",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"servicebinding.io/type":"mysql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name": "my-uid",
+													"namespace": "my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"servicebinding.io/type":"mysql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												},
+												"type":"servicebinding.io/mysql",
+												"data":{
+													"type":"bXlzcWw="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"KeepObservedSecretType": {
+			reason: "Secret type of an already observed binding secret is not changed",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							SecretType: "servicebinding.io/mysql",
+							BindingSecretOverrides: map[string]string{
+								"type": "mysql",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret"
+											}
+										}
+									}
+								}`),
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot change type of binding secret from "Opaque" to "servicebinding.io/mysql" after it has been created`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"servicebinding.io/type":"mysql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name": "my-uid",
+													"namespace": "my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"servicebinding.io/type":"mysql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"type":"bXlzcWw="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"StrictValidation": {
+			reason: "Violations of strict validation are fatal and the binding is not published",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							Validation: &v1alpha1.Validation{Strict: true},
+							BindingSecretOverrides: map[string]string{
+								"type":     "redis",
+								"provider": "bitnami",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"cache": {
+								ConnectionDetails: map[string][]byte{
+									"host": []byte("my-host"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `binding of type "redis" does not have an optional password entry`,
+						},
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `binding of type "redis" does not have an optional ssl entry`,
+						},
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_FATAL,
+							Message:  `invalid binding: binding of type "redis" does not have a port entry`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+		},
+		"WithholdIncompleteBinding": {
+			reason: "Binding name is not published until the binding meets its publish conditions",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							PublishConditions: &v1alpha1.PublishConditions{
+								RequiredKeys:          []string{"password"},
+								RequireReadyResources: true,
+								ReadyResources:        []string{"database"},
+							},
+							BindingSecretOverrides: map[string]string{
+								"type": "my-type",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								},
+								"status":{
+									"conditions":[
+										{
+											"type":"BindingReady",
+											"status":"False",
+											"reason":"Incomplete",
+											"message":"composed resource \"database\" is not ready",
+											"lastTransitionTime":"2023-11-01T00:00:00Z"
+										}
+									]
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"example.org/v1",
+									"kind":"Database",
+									"status":{
+										"conditions":[
+											{
+												"type":"Ready",
+												"status":"False",
+												"reason":"Creating",
+												"lastTransitionTime":"2023-11-01T00:00:00Z"
+											}
+										]
+									}
+								}`),
+								ConnectionDetails: map[string][]byte{
+									"password": []byte("my-password"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_NORMAL,
+							Message:  `binding is not ready: composed resource "database" is not ready`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"conditions":[
+										{
+											"type":"BindingReady",
+											"status":"False",
+											"reason":"Incomplete",
+											"message":"composed resource \"database\" is not ready",
+											"lastTransitionTime":"2023-11-01T00:00:00Z"
+										}
+									]
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"servicebinding.io/type":"my-type"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name": "my-uid",
+													"namespace": "my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"servicebinding.io/type":"my-type"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"password":"bXktcGFzc3dvcmQ=",
+													"type":"bXktdHlwZQ=="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ClaimNameSecretName": {
+			reason: "Binding secret is named after the claim and its Object after the namespaced secret name",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							SecretName: &v1alpha1.SecretName{
+								Strategy: v1alpha1.SecretNameStrategyClaimName,
+								Suffix:   "-binding",
+							},
+							BindingSecretOverrides: map[string]string{
+								"type": "mysql",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-claim-binding"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"name":"my-namespace.my-claim-binding",
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"servicebinding.io/type":"mysql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name": "my-claim-binding",
+													"namespace": "my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"servicebinding.io/type":"mysql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"type":"bXlzcWw="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"InvalidSecretName": {
+			reason: "A rendered binding secret name that is not a valid secret name is fatal",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							SecretName: &v1alpha1.SecretName{
+								Strategy: v1alpha1.SecretNameStrategyTemplate,
+								Template: "{{.claim.kind}}-{{.claim.name}}",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"kind":"MyClaim",
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_FATAL,
+							Message:  `cannot determine name of binding secret: invalid binding secret name "MyClaim-my-claim": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+		},
+		"CustomLabelsAndAnnotations": {
+			reason: "Binding secret and Object carry standard and rendered custom labels and annotations",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							BindingSecretOverrides: map[string]string{
+								"type":     "mysql",
+								"provider": "bitnami",
+							},
+							Labels: map[string]string{
+								"example.org/team": "{{.xr.metadata.labels.team}}",
+								"example.org/env":  "{{.xr.metadata.labels.env}}",
+							},
+							Annotations: map[string]string{
+								"example.org/owner": "{{.claim.name}} in {{.claim.namespace}}",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"name":"my-xr",
+									"uid":"my-uid",
+									"labels":{
+										"team":"my-team"
+									}
+								},
+								"spec":{
+									"claimRef":{
+										"apiVersion":"example.org/v1",
+										"kind":"MySQLInstance",
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot render label "example.org/env": template: example.org/env:1:5: executing "example.org/env" at <.xr.metadata.labels.env>: map has no entry for key "env"`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"crossplane.io/composite":"my-xr",
+											"example.org/team":"my-team",
+											"servicebinding.io/claim-kind":"MySQLInstance",
+											"servicebinding.io/provider":"bitnami",
+											"servicebinding.io/type":"mysql"
+										},
+										"annotations":{
+											"example.org/owner":"my-claim in my-namespace",
+											"servicebinding.io/provisioned-service":"{\"kind\":\"MySQLInstance\",\"namespace\":\"my-namespace\",\"name\":\"my-claim\",\"apiVersion\":\"example.org/v1\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"crossplane.io/composite":"my-xr",
+														"example.org/team":"my-team",
+														"servicebinding.io/claim-kind":"MySQLInstance",
+														"servicebinding.io/provider":"bitnami",
+														"servicebinding.io/type":"mysql"
+													},
+													"annotations":{
+														"example.org/owner":"my-claim in my-namespace",
+														"servicebinding.io/provisioned-service":"{\"kind\":\"MySQLInstance\",\"namespace\":\"my-namespace\",\"name\":\"my-claim\",\"apiVersion\":\"example.org/v1\"}"
+													},
+													"name":"my-uid",
+													"namespace":"my-namespace",
+													"creationTimestamp":null
+												},
+												"data":{
+													"provider":"Yml0bmFtaQ==",
+													"type":"bXlzcWw="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ComposeServiceBinding": {
+			reason: "The function should compose a ServiceBinding for the workload specified by the claim's annotation",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ServiceBinding: &v1alpha1.ServiceBinding{
+								Name:        "{{.claim.name}}-orders",
+								BindingName: "db",
+								Workload: v1alpha1.Workload{
+									Annotation: "example.org/workload",
+									FieldPath:  "spec.parameters.workload",
+								},
+								Env: []v1alpha1.EnvMapping{
+									{Name: "DB_PASSWORD", Key: "password"},
+								},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"name":"my-xr",
+									"uid":"my-uid",
+									"annotations":{
+										"example.org/workload":"orders"
+									}
+								},
+								"spec":{
+									"claimRef":{
+										"apiVersion":"example.org/v1",
+										"kind":"Claim",
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"parameters":{
+										"workload":"ignored"
+									},
+									"writeConnectionSecretToRef":{
+										"name":"my-secret",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-secret"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"servicebinding": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"crossplane.io/composite":"my-xr",
+											"servicebinding.io/claim-kind":"Claim"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"servicebinding.io/v1beta1",
+												"kind":"ServiceBinding",
+												"metadata":{
+													"name":"my-claim-orders",
+													"namespace":"my-namespace",
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"crossplane.io/composite":"my-xr",
+														"servicebinding.io/claim-kind":"Claim"
+													}
+												},
+												"spec":{
+													"name":"db",
+													"service":{
+														"apiVersion":"example.org/v1",
+														"kind":"Claim",
+														"name":"my-claim"
+													},
+													"workload":{
+														"apiVersion":"apps/v1",
+														"kind":"Deployment",
+														"name":"orders"
+													},
+													"env":[
+														{"name":"DB_PASSWORD","key":"password"}
+													]
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ServiceBindingWorkloadSelector": {
+			reason: "The function should select the workload using the label selector read from the XR's field",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ServiceBinding: &v1alpha1.ServiceBinding{
+								Workload: v1alpha1.Workload{
+									APIVersion:    "apps/v1",
+									Kind:          "StatefulSet",
+									Annotation:    "example.org/workload",
+									FieldPath:     "spec.parameters.workload",
+									ReferenceType: v1alpha1.WorkloadReferenceTypeSelector,
+								},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"apiVersion":"example.org/v1",
+										"kind":"Claim",
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"parameters":{
+										"workload":"app=orders"
+									},
+									"writeConnectionSecretToRef":{
+										"name":"my-secret",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-secret"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"servicebinding": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"servicebinding.io/claim-kind":"Claim"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"servicebinding.io/v1beta1",
+												"kind":"ServiceBinding",
+												"metadata":{
+													"name":"my-claim",
+													"namespace":"my-namespace",
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"servicebinding.io/claim-kind":"Claim"
+													}
+												},
+												"spec":{
+													"service":{
+														"apiVersion":"example.org/v1",
+														"kind":"Claim",
+														"name":"my-claim"
+													},
+													"workload":{
+														"apiVersion":"apps/v1",
+														"kind":"StatefulSet",
+														"selector":{
+															"matchLabels":{
+																"app":"orders"
+															}
+														}
+													}
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ServiceBindingWithoutWorkload": {
+			reason: "The function should not compose a ServiceBinding if the claim does not specify a workload",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ServiceBinding: &v1alpha1.ServiceBinding{
+								Workload: v1alpha1.Workload{
+									Annotation: "example.org/workload",
+								},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"writeConnectionSecretToRef":{
+										"name":"my-secret",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-secret"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{},
+					},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_NORMAL,
+							Message:  "claim does not specify a workload, not composing a ServiceBinding",
+						},
+					},
+				},
+			},
+		},
+		"ReflectServiceBindingStatus": {
+			reason: "The function should reflect the Ready condition and workload of the observed ServiceBinding in the XR's status",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ServiceBinding: &v1alpha1.ServiceBinding{
+								Workload: v1alpha1.Workload{
+									Annotation: "example.org/workload",
+								},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid",
+									"annotations":{
+										"example.org/workload":"orders"
+									}
+								},
+								"spec":{
+									"claimRef":{
+										"apiVersion":"example.org/v1",
+										"kind":"Claim",
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"writeConnectionSecretToRef":{
+										"name":"my-secret",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"servicebinding": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"status":{
+										"atProvider":{
+											"manifest":{
+												"apiVersion":"servicebinding.io/v1beta1",
+												"kind":"ServiceBinding",
+												"metadata":{
+													"name":"my-claim",
+													"namespace":"my-namespace"
+												},
+												"spec":{
+													"workload":{
+														"apiVersion":"apps/v1",
+														"kind":"Deployment",
+														"name":"orders"
+													}
+												},
+												"status":{
+													"conditions":[
+														{
+															"type":"Ready",
+															"status":"False",
+															"reason":"WorkloadNotFound",
+															"message":"workload orders not found",
+															"lastTransitionTime":"2023-11-01T00:00:00Z"
+														}
+													]
+												}
+											}
+										}
+									}
+								}`),
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-secret",
+										"serviceBinding":{
+											"name":"my-claim",
+											"ready":"False",
+											"reason":"WorkloadNotFound",
+											"message":"workload orders not found",
+											"workload":{
+												"apiVersion":"apps/v1",
+												"kind":"Deployment",
+												"name":"orders"
+											}
+										}
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"servicebinding": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"servicebinding.io/claim-kind":"Claim"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"servicebinding.io/v1beta1",
+												"kind":"ServiceBinding",
+												"metadata":{
+													"name":"my-claim",
+													"namespace":"my-namespace",
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"servicebinding.io/claim-kind":"Claim"
+													}
+												},
+												"spec":{
+													"service":{
+														"apiVersion":"example.org/v1",
+														"kind":"Claim",
+														"name":"my-claim"
+													},
+													"workload":{
+														"apiVersion":"apps/v1",
+														"kind":"Deployment",
+														"name":"orders"
+													}
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"EnrichClaimConnectionSecret": {
+			reason: "The function should compose a binding secret from the entries of the claim's connection secret and the overrides",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ClaimConnectionSecretMode: v1alpha1.ClaimConnectionSecretModeEnrich,
+							BindingSecretOverrides: map[string]string{
+								"type": "mysql",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"writeConnectionSecretToRef":{
+										"name":"my-secret",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+							ConnectionDetails: map[string][]byte{
+								"username": []byte("my-user"),
+								"password": []byte("my-password"),
+							},
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								ConnectionDetails: map[string][]byte{
+									"username":   []byte("my-user"),
+									"password":   []byte("my-password"),
+									"root-token": []byte("not-published"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"servicebinding.io/type":"mysql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name":"my-uid",
+													"namespace":"my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"servicebinding.io/type":"mysql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"password":"bXktcGFzc3dvcmQ=",
+													"username":"bXktdXNlcg==",
+													"type":"bXlzcWw="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"EnrichedSecretNameCollision": {
+			reason: "The function should not compose a binding secret replacing the claim's connection secret",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ClaimConnectionSecretMode: v1alpha1.ClaimConnectionSecretModeEnrich,
+							SecretName: &v1alpha1.SecretName{
+								Strategy: v1alpha1.SecretNameStrategyClaimName,
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"writeConnectionSecretToRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_FATAL,
+							Message:  "cannot determine name of binding secret, \"my-claim\" is the name of the claim's connection secret",
+						},
+					},
+				},
+			},
+		},
+		"ObjectPolicies": {
+			reason: "The function should apply the configured policies to the provider-kubernetes Object",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ObjectPolicies: &v1alpha1.ObjectPolicies{
+								ManagementPolicy: "ObserveCreateUpdate",
+								ReadinessPolicy:  "DeriveFromObject",
+								DeletionPolicy:   "Orphan",
+							},
+							BindingSecretOverrides: map[string]string{
+								"type": "mysql",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"servicebinding.io/type":"mysql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name":"my-uid",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"servicebinding.io/type":"mysql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"type":"bXlzcWw="
+												}
+											}
+										},
+										"managementPolicy":"ObserveCreateUpdate",
+										"readiness":{
+											"policy":"DeriveFromObject"
+										},
+										"deletionPolicy":"Orphan",
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"DependsOn": {
+			reason: "The function should declare the configured dependencies and the composed namespace of the binding secret",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							DependsOn: []v1alpha1.DependsOn{
+								{Name: "{{.xr.metadata.name}}-release"},
+							},
+							DetectNamespaceDependency: true,
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"name":"my-xr",
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"namespace": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Namespace",
+												"metadata":{
+													"name":"my-namespace"
+												}
+											}
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"namespace": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Namespace",
+												"metadata":{
+													"name":"my-namespace"
+												}
+											}
+										}
+									}
+								}`),
+							},
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"crossplane.io/composite":"my-xr"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name":"my-uid",
+													"namespace":"my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"crossplane.io/composite":"my-xr"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												}
+											}
+										},
+										"references":[
+											{
+												"dependsOn":{
+													"name":"my-xr-release"
+												}
+											},
+											{
+												"dependsOn":{
+													"apiVersion":"v1",
+													"kind":"Namespace",
+													"name":"my-namespace"
+												}
+											}
+										],
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"CustomResourceName": {
+			reason: "The function should compose the binding secret under the configured resource name next to the resources of earlier steps",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ResourceName: "app-binding",
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"kubernetes.crossplane.io/v1alpha1","kind":"Object"}`),
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"kubernetes.crossplane.io/v1alpha1","kind":"Object"}`),
+							},
+							"app-binding": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name":"my-uid",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+													}
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ResourceNameConflict": {
+			reason: "The function should not replace a resource composed by an earlier step of the pipeline",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"kubernetes.crossplane.io/v1alpha1","kind":"Object"}`),
+							},
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{"apiVersion":"kubernetes.crossplane.io/v1alpha1","kind":"Object"}`),
+							},
+						},
+					},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_FATAL,
+							Message:  "cannot compose binding secret, an earlier step of the pipeline already composes resource \"bindingsecret\"",
+						},
+					},
+				},
+			},
+		},
+		"MultipleBindings": {
+			reason: "The function should compose a binding secret per binding and list them in the XR's status",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ConnectionDetailsMode: v1alpha1.ConnectionDetailsModeMapped,
+							BindingSecretOverrides: map[string]string{
+								"type": "mysql",
+							},
+							Bindings: []v1alpha1.Binding{
+								{
+									Name: "app",
+									BindingKeys: []v1alpha1.BindingKey{
+										{Name: "username", ResourceName: "database"},
+										{Name: "password", ResourceName: "database"},
+									},
+								},
+								{
+									Name:    "admin",
+									Primary: true,
+									BindingKeys: []v1alpha1.BindingKey{
+										{Name: "password", ResourceName: "database", ConnectionDetailKey: "admin-password"},
+									},
+									BindingSecretOverrides: map[string]string{
+										"username": "root",
+									},
+									StatusFieldPath: "status.adminBinding.name",
+								},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								ConnectionDetails: map[string][]byte{
+									"username":       []byte("app-user"),
+									"password":       []byte("app-password"),
+									"admin-password": []byte("admin-password"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid-admin"
+									},
+									"bindings":[
+										{"name":"app","secretName":"my-uid-app"},
+										{"name":"admin","secretName":"my-uid-admin"}
+									],
+									"adminBinding":{
+										"name":"my-uid-admin"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret-app": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"servicebinding.io/type":"mysql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name":"my-uid-app",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"servicebinding.io/type":"mysql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"password":"YXBwLXBhc3N3b3Jk",
+													"type":"bXlzcWw=",
+													"username":"YXBwLXVzZXI="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+							"bindingsecret-admin": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"servicebinding.io/type":"mysql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name":"my-uid-admin",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"servicebinding.io/type":"mysql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"password":"YWRtaW4tcGFzc3dvcmQ=",
+													"type":"bXlzcWw=",
+													"username":"cm9vdA=="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"SeveralPrimaryBindings": {
+			reason: "The function should reject more than one primary binding",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							Bindings: []v1alpha1.Binding{
+								{Name: "app", Primary: true},
+								{Name: "admin", Primary: true},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_FATAL,
+							Message:  "invalid bindings: bindings \"app\" and \"admin\" cannot both be the primary binding",
+						},
+					},
+				},
+			},
+		},
+		"MetadataEntries": {
+			reason: "The function should derive binding entries from the XR's annotations, labels and composition selector",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							MetadataEntries: map[string]string{
+								"type":         "type",
+								"provider":     "provider",
+								"architecture": "architecture",
+							},
+							BindingSecretOverrides: map[string]string{
+								"type": "postgresql",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid",
+									"annotations":{
+										"provider":"bitnami"
+									}
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim"
+									},
+									"compositionSelector":{
+										"matchLabels":{
+											"architecture":"standalone",
+											"provider":"other",
+											"type":"mysql"
+										}
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"servicebinding.io/provider":"bitnami",
+											"servicebinding.io/type":"postgresql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name":"my-uid",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"servicebinding.io/provider":"bitnami",
+														"servicebinding.io/type":"postgresql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"architecture":"c3RhbmRhbG9uZQ==",
+													"provider":"Yml0bmFtaQ==",
+													"type":"cG9zdGdyZXNxbA=="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ClaimOverrides": {
+			reason: "The function should apply the settings the claim is allowed to customize using annotations, extra keys do not replace existing entries",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ClaimOverrides: []v1alpha1.ClaimOverride{
+								v1alpha1.ClaimOverrideName,
+								v1alpha1.ClaimOverrideExtraKeys,
+							},
+							BindingSecretOverrides: map[string]string{
+								"type": "mysql",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid",
+									"annotations":{
+										"binding.servicebinding.io/name":"orders-db",
+										"binding.servicebinding.io/extra-keys":"{\"host\":\"other.example.org\",\"ssl-mode\":\"required\",\"type\":\"postgresql\"}",
+										"binding.servicebinding.io/skip":"true"
+									}
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								ConnectionDetails: map[string][]byte{
+									"host": []byte("db.example.org"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"orders-db"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"name":"my-namespace.orders-db",
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"servicebinding.io/type":"mysql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name":"orders-db",
+													"namespace":"my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"servicebinding.io/type":"mysql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"host":"ZGIuZXhhbXBsZS5vcmc=",
+													"ssl-mode":"cmVxdWlyZWQ=",
+													"type":"bXlzcWw="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  "ignoring annotation \"binding.servicebinding.io/skip\", claims are not allowed to customize the binding's Skip",
+						},
+					},
+				},
+			},
+		},
+		"ClaimSkipsBinding": {
+			reason: "The function should not compose a binding for a claim that skips it",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ClaimOverrides: []v1alpha1.ClaimOverride{
+								v1alpha1.ClaimOverrideSkip,
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid",
+									"annotations":{
+										"binding.servicebinding.io/skip":"true"
+									}
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_NORMAL,
+							Message:  "claim skips its binding using annotation \"binding.servicebinding.io/skip\", nothing to do",
+						},
+					},
+				},
+			},
+		},
+		"ClaimlessTargetNamespace": {
+			reason: "The function should bind an XR without a claim into the target namespace specified by its annotation",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							TargetNamespace: &v1alpha1.TargetNamespace{
+								Name:       "default-bindings",
+								Annotation: "example.org/binding-namespace",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"name":"my-xr",
+									"uid":"my-uid",
+									"annotations":{
+										"example.org/binding-namespace":"apps"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/composite":"my-xr"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"kind\":\"XR\",\"name\":\"my-xr\",\"apiVersion\":\"example.org/v1\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name":"my-uid",
+													"namespace":"apps",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/composite":"my-xr"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"kind\":\"XR\",\"name\":\"my-xr\",\"apiVersion\":\"example.org/v1\"}"
+													}
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"NamespacedComposite": {
+			reason: "The function should bind a namespaced XR in its own namespace using a namespaced Object",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ObjectPolicies: &v1alpha1.ObjectPolicies{
+								DeletionPolicy: "Orphan",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"name":"my-xr",
+									"namespace":"apps",
+									"uid":"my-uid"
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.m.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"namespace":"apps",
+										"labels":{
+											"crossplane.io/composite":"my-xr"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"kind\":\"XR\",\"namespace\":\"apps\",\"name\":\"my-xr\",\"apiVersion\":\"example.org/v1\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name":"my-uid",
+													"namespace":"apps",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/composite":"my-xr"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"kind\":\"XR\",\"namespace\":\"apps\",\"name\":\"my-xr\",\"apiVersion\":\"example.org/v1\"}"
+													}
+												}
+											}
+										},
+										"managementPolicies":["Observe","Create","Update","LateInitialize"],
+										"providerConfigRef":{
+											"kind":"ClusterProviderConfig",
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ComposeSecretDirectly": {
+			reason: "The function should compose the binding secret and the ServiceBinding directly rather than as provider-kubernetes Objects",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							SecretComposition: v1alpha1.SecretCompositionSecret,
+							DependsOn: []v1alpha1.DependsOn{
+								{APIVersion: "v1", Kind: "ConfigMap", Name: "ignored", Namespace: "{{.claim.namespace}}"},
+							},
+							ServiceBinding: &v1alpha1.ServiceBinding{
+								Workload: v1alpha1.Workload{
+									Annotation: "example.org/workload",
+								},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"name":"my-xr",
+									"uid":"my-uid",
+									"annotations":{
+										"example.org/workload":"orders"
+									}
+								},
+								"spec":{
+									"claimRef":{
+										"apiVersion":"example.org/v1",
+										"kind":"Claim",
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								ConnectionDetails: map[string][]byte{
+									"username": []byte("their-user"),
+									"password": []byte("their-password"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"v1",
+									"kind":"Secret",
+									"metadata":{
+										"name":"my-uid",
+										"namespace":"my-namespace",
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"crossplane.io/composite":"my-xr",
+											"servicebinding.io/claim-kind":"Claim"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"kind\":\"Claim\",\"namespace\":\"my-namespace\",\"name\":\"my-claim\",\"apiVersion\":\"example.org/v1\"}"
+										}
+									},
+									"data":{
+										"password":"dGhlaXItcGFzc3dvcmQ=",
+										"username":"dGhlaXItdXNlcg=="
+									}
+								}`),
+								Ready: fnv1beta1.Ready_READY_TRUE,
+							},
+							"servicebinding": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"servicebinding.io/v1beta1",
+									"kind":"ServiceBinding",
+									"metadata":{
+										"name":"my-claim",
+										"namespace":"my-namespace",
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"crossplane.io/composite":"my-xr",
+											"servicebinding.io/claim-kind":"Claim"
+										}
+									},
+									"spec":{
+										"service":{
+											"apiVersion":"example.org/v1",
+											"kind":"Claim",
+											"name":"my-claim"
+										},
+										"workload":{
+											"apiVersion":"apps/v1",
+											"kind":"Deployment",
+											"name":"orders"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ComposeExternalSecret": {
+			reason: "The function should compose an ExternalSecret reading the binding's credentials from the secret store and rendering templates reading them",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							SecretComposition: v1alpha1.SecretCompositionExternalSecret,
+							ExternalSecret: &v1alpha1.ExternalSecret{
+								SecretStoreRef: v1alpha1.SecretStoreRef{
+									Name: "vault",
+								},
+								RemoteKey:       "bindings/{{.claim.namespace}}/{{.claim.name}}",
+								RefreshInterval: "1h",
+							},
+							SecretType: "servicebinding.io/postgresql",
+							BindingSecretOverrides: map[string]string{
+								"type": "postgresql",
+							},
+							ConnectionDetailsMode: v1alpha1.ConnectionDetailsModeMapped,
+							BindingKeys: []v1alpha1.BindingKey{
+								{Name: "user", ResourceName: "database", ConnectionDetailKey: "username"},
+								{Name: "password", ResourceName: "database"},
+							},
+							BindingSecretTemplates: map[string]string{
+								"uri": "postgresql://{{ .user }}:{{ .password }}@db",
+								"dsn": "{{ .xr.metadata.name }}:{{ .password }}",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"name":"my-xr",
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"apiVersion":"example.org/v1",
+										"kind":"Claim",
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								ConnectionDetails: map[string][]byte{
+									"username": []byte("their-user"),
+									"password": []byte("their-password"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot render binding key "dsn" from the secret store, its template reads values that are not read from the secret store`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"external-secrets.io/v1beta1",
+									"kind":"ExternalSecret",
+									"metadata":{
+										"name":"my-uid",
+										"namespace":"my-namespace",
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"crossplane.io/composite":"my-xr",
+											"servicebinding.io/claim-kind":"Claim",
+											"servicebinding.io/type":"postgresql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"kind\":\"Claim\",\"namespace\":\"my-namespace\",\"name\":\"my-claim\",\"apiVersion\":\"example.org/v1\"}"
+										}
+									},
+									"spec":{
+										"refreshInterval":"1h",
+										"secretStoreRef":{
+											"name":"vault",
+											"kind":"SecretStore"
+										},
+										"target":{
+											"name":"my-uid",
+											"creationPolicy":"Owner",
+											"template":{
+												"type":"servicebinding.io/postgresql",
+												"mergePolicy":"Merge",
+												"metadata":{
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"crossplane.io/composite":"my-xr",
+														"servicebinding.io/claim-kind":"Claim",
+														"servicebinding.io/type":"postgresql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"kind\":\"Claim\",\"namespace\":\"my-namespace\",\"name\":\"my-claim\",\"apiVersion\":\"example.org/v1\"}"
+													}
+												},
+												"data":{
+													"type":"postgresql",
+													"uri":"postgresql://{{ .user }}:{{ .password }}@db"
+												}
+											}
+										},
+										"data":[
+											{"secretKey":"password","remoteRef":{"key":"bindings/my-namespace/my-claim","property":"password"}},
+											{"secretKey":"user","remoteRef":{"key":"bindings/my-namespace/my-claim","property":"username"}}
+										]
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"PublishedConnectionDetailsExternalSecret": {
+			reason: "The function should compose an ExternalSecret reading the connection details the XR publishes to a secret store",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ConnectionDetailsStores: []v1alpha1.ConnectionDetailsStore{
+								{
+									StoreConfig: "vault",
+									SecretStoreRef: &v1alpha1.SecretStoreRef{
+										Name: "vault",
+										Kind: "ClusterSecretStore",
+									},
+									KeyPrefix: "crossplane-system/",
+								},
+							},
+							BindingSecretOverrides: map[string]string{
+								"type": "mysql",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"publishConnectionDetailsTo":{
+										"name":"my-xr",
+										"configRef":{
+											"name":"vault"
+										}
+									}
+								}
+							}`),
+							ConnectionDetails: map[string][]byte{
+								"password": []byte("my-password"),
+							},
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								ConnectionDetails: map[string][]byte{
+									"password": []byte("my-password"),
+									"port":     []byte("3306"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot read connection detail "port" of composed resource "database" for binding key "port" from secret store, the composite resource does not publish it`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"external-secrets.io/v1beta1",
+									"kind":"ExternalSecret",
+									"metadata":{
+										"name":"my-uid",
+										"namespace":"my-namespace",
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"servicebinding.io/type":"mysql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"secretStoreRef":{
+											"name":"vault",
+											"kind":"ClusterSecretStore"
+										},
+										"target":{
+											"name":"my-uid",
+											"creationPolicy":"Owner",
+											"template":{
+												"mergePolicy":"Merge",
+												"metadata":{
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"servicebinding.io/type":"mysql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"type":"mysql"
+												}
+											}
+										},
+										"data":[
+											{
+												"secretKey":"password",
+												"remoteRef":{
+													"key":"crossplane-system/my-xr",
+													"property":"password"
+												}
+											}
+										]
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"PublishedConnectionDetailsReference": {
+			reason: "The function should reference the connection details the XR publishes to a Kubernetes secret store",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ConnectionDetailsStores: []v1alpha1.ConnectionDetailsStore{
+								{
+									Namespace: "crossplane-system",
+								},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"publishConnectionDetailsTo":{
+										"name":"my-xr"
+									}
+								}
+							}`),
+							ConnectionDetails: map[string][]byte{
+								"password": []byte("my-password"),
+							},
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								ConnectionDetails: map[string][]byte{
+									"password": []byte("my-password"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name": "my-uid",
+													"namespace": "my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												}
+											}
+										},
+										"references":[
+											{
+												"patchesFrom":{
+													"apiVersion":"v1",
+													"kind":"Secret",
+													"name":"my-xr",
+													"namespace":"crossplane-system",
+													"fieldPath":"data.password"
+												},
+												"toFieldPath":"data.password"
+											}
+										],
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ImportConnectionSecret": {
+			reason: "The function should share a connection secret in another namespace with the claim's namespace using secretgen-controller",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							CrossNamespaceConnectionSecretMode: v1alpha1.CrossNamespaceConnectionSecretModeImport,
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"name":"my-xr",
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"apiVersion":"example.org/v1",
+										"kind":"Claim",
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"writeConnectionSecretToRef":{
+										"name":"xr-secret",
+										"namespace":"crossplane-system"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								ConnectionDetails: map[string][]byte{
+									"password": []byte("their-password"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"xr-secret"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"secretexport": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"crossplane.io/composite":"my-xr",
+											"servicebinding.io/claim-kind":"Claim"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"secretgen.carvel.dev/v1alpha1",
+												"kind":"SecretExport",
+												"metadata":{
+													"name":"xr-secret",
+													"namespace":"crossplane-system",
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"crossplane.io/composite":"my-xr",
+														"servicebinding.io/claim-kind":"Claim"
+													}
+												},
+												"spec":{
+													"toNamespace":"my-namespace"
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"crossplane.io/composite":"my-xr",
+											"servicebinding.io/claim-kind":"Claim"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"secretgen.carvel.dev/v1alpha1",
+												"kind":"SecretImport",
+												"metadata":{
+													"name":"xr-secret",
+													"namespace":"my-namespace",
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"crossplane.io/composite":"my-xr",
+														"servicebinding.io/claim-kind":"Claim"
+													}
+												},
+												"spec":{
+													"fromNamespace":"crossplane-system"
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"InvalidFieldPathBindingKeys": {
+			reason: "Binding keys without a field path or reading a null field should be skipped rather than set to a formatted nil value",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ConnectionDetailsMode: v1alpha1.ConnectionDetailsModeMapped,
+							BindingKeys: []v1alpha1.BindingKey{
+								{Name: "region", Type: v1alpha1.BindingKeySourceFromCompositeFieldPath},
+								{Name: "zone", Type: v1alpha1.BindingKeySourceFromCompositeFieldPath, FieldPath: "spec.parameters.zone"},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"parameters":{
+										"zone":null
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot read value for binding key "region": fieldPath must be specified for binding keys of type FromCompositeFieldPath`,
+						},
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot read value for binding key "zone": cannot get field "spec.parameters.zone" of composite resource: field is null`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name": "my-uid",
+													"namespace": "my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"KeepObservedDerivedSecretType": {
+			reason: "An already observed binding secret keeps its type rather than being dropped if its type cannot be derived",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							DeriveSecretType: true,
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"type":"servicebinding.io/mysql"
+											}
+										}
+									}
+								}`),
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot derive type of binding secret, binding does not have a type entry, keeping type "servicebinding.io/mysql"`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name": "my-uid",
+													"namespace": "my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
+												},
+												"type":"servicebinding.io/mysql"
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"WithholdIncompleteConnectionSecret": {
+			reason: "The claim's connection secret is not published as the binding until it meets the publish conditions",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							PublishConditions: &v1alpha1.PublishConditions{
+								RequiredKeys: []string{"password"},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"writeConnectionSecretToRef":{
+										"name":"my-secret",
+										"namespace":"my-namespace"
+									}
+								},
+								"status":{
+									"conditions":[
+										{
+											"type":"BindingReady",
+											"status":"False",
+											"reason":"Incomplete",
+											"message":"binding does not have a password entry",
+											"lastTransitionTime":"2023-11-01T00:00:00Z"
+										}
+									]
+								}
+							}`),
+							ConnectionDetails: map[string][]byte{
+								"type":     []byte("my-type"),
+								"username": []byte("my-user"),
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_NORMAL,
+							Message:  `binding is not ready: binding does not have a password entry`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"conditions":[
+										{
+											"type":"BindingReady",
+											"status":"False",
+											"reason":"Incomplete",
+											"message":"binding does not have a password entry",
+											"lastTransitionTime":"2023-11-01T00:00:00Z"
+										}
+									]
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{},
+					},
+				},
+			},
+		},
+		"IgnoreBindingSecretSettings": {
+			reason: "Settings of the binding secret do not apply to the claim's connection secret",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							RequireWriteConnectionSecretToRef: true,
+							BindingSecretOverrides: map[string]string{
+								"type": "my-type",
+							},
+							BindingSecretTemplates: map[string]string{
+								"uri": "my-type://{{ .host }}",
+							},
+							MetadataEntries: map[string]string{
+								"type": "example.org/type",
+							},
+							SecretType: "servicebinding.io/my-type",
+							Labels: map[string]string{
+								"team": "orders",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"writeConnectionSecretToRef":{
+										"name":"my-secret",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  "claim's connection secret is used as the binding secret as is, ignoring bindingSecretOverrides, bindingSecretTemplates, metadataEntries, secretType, labels, set claimConnectionSecretMode to Enrich to apply them",
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-secret"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{},
+					},
+				},
+			},
+		},
+		"WithholdIncompleteBindings": {
+			reason: "Every binding is listed in the XR's status once it meets the publish conditions on its own",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ConnectionDetailsMode: v1alpha1.ConnectionDetailsModeMapped,
+							PublishConditions: &v1alpha1.PublishConditions{
+								RequiredKeys: []string{"host"},
+							},
+							BindingSecretOverrides: map[string]string{
+								"type": "my-type",
+							},
+							Bindings: []v1alpha1.Binding{
+								{
+									Name: "app",
+									BindingKeys: []v1alpha1.BindingKey{
+										{Name: "username", ResourceName: "database"},
+										{Name: "password", ResourceName: "database"},
+									},
+									BindingSecretOverrides: map[string]string{
+										"host": "db.example.org",
+									},
+								},
+								{
+									Name:    "admin",
+									Primary: true,
+									BindingKeys: []v1alpha1.BindingKey{
+										{Name: "password", ResourceName: "database", ConnectionDetailKey: "admin-password"},
+									},
+									BindingSecretOverrides: map[string]string{
+										"username": "root",
+									},
+									StatusFieldPath: "status.adminBinding.name",
+								},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim"
+									}
+								},
+								"status":{
+									"conditions":[
+										{
+											"type":"BindingReady",
+											"status":"False",
+											"reason":"Incomplete",
+											"message":"binding \"admin\": binding does not have a host entry",
+											"lastTransitionTime":"2023-11-01T00:00:00Z"
+										}
+									]
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								ConnectionDetails: map[string][]byte{
+									"username":       []byte("app-user"),
+									"password":       []byte("app-password"),
+									"admin-password": []byte("admin-password"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_NORMAL,
+							Message:  `binding is not ready: binding "admin": binding does not have a host entry`,
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"bindings":[
+										{"name":"app","secretName":"my-uid-app"}
+									],
+									"conditions":[
+										{
+											"type":"BindingReady",
+											"status":"False",
+											"reason":"Incomplete",
+											"message":"binding \"admin\": binding does not have a host entry",
+											"lastTransitionTime":"2023-11-01T00:00:00Z"
+										}
+									]
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret-app": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"servicebinding.io/type":"my-type"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name":"my-uid-app",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"servicebinding.io/type":"my-type"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"host":"ZGIuZXhhbXBsZS5vcmc=",
+													"password":"YXBwLXBhc3N3b3Jk",
+													"type":"bXktdHlwZQ==",
+													"username":"YXBwLXVzZXI="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+							"bindingsecret-admin": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"servicebinding.io/type":"my-type"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name":"my-uid-admin",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"servicebinding.io/type":"my-type"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"name\":\"my-claim\"}"
+													}
+												},
+												"data":{
+													"password":"YWRtaW4tcGFzc3dvcmQ=",
+													"type":"bXktdHlwZQ==",
+													"username":"cm9vdA=="
+												}
+											}
+										},
+										"providerConfigRef":{
+											"name":"default"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"PublishDirectlyComposedBinding": {
+			reason: "Resources composed by the function itself, e.g. a directly composed ServiceBinding, are not required to be ready by default",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							SecretComposition: v1alpha1.SecretCompositionSecret,
+							PublishConditions: &v1alpha1.PublishConditions{
+								RequireReadyResources: true,
+							},
+							ServiceBinding: &v1alpha1.ServiceBinding{
+								Workload: v1alpha1.Workload{
+									Annotation: "example.org/workload",
+								},
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"name":"my-xr",
+									"uid":"my-uid",
+									"annotations":{
+										"example.org/workload":"orders"
+									}
+								},
+								"spec":{
+									"claimRef":{
+										"apiVersion":"example.org/v1",
+										"kind":"Claim",
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								},
+								"status":{
+									"conditions":[
+										{
+											"type":"BindingReady",
+											"status":"True",
+											"reason":"Available",
+											"lastTransitionTime":"2023-11-01T00:00:00Z"
+										}
+									]
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"database": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"example.org/v1",
+									"kind":"Database",
+									"status":{
+										"conditions":[
+											{
+												"type":"Ready",
+												"status":"True",
+												"reason":"Available",
+												"lastTransitionTime":"2023-11-01T00:00:00Z"
+											}
+										]
+									}
+								}`),
+								ConnectionDetails: map[string][]byte{
+									"type":     []byte("my-type"),
+									"username": []byte("their-user"),
+									"password": []byte("their-password"),
+								},
+							},
+							"servicebinding": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"servicebinding.io/v1beta1",
+									"kind":"ServiceBinding",
+									"status":{
+										"conditions":[
+											{
+												"type":"Ready",
+												"status":"False",
+												"reason":"ServiceNotReady",
+												"lastTransitionTime":"2023-11-01T00:00:00Z"
+											}
+										]
+									}
+								}`),
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-uid"
+									},
+									"conditions":[
+										{
+											"type":"BindingReady",
+											"status":"True",
+											"reason":"Available",
+											"lastTransitionTime":"2023-11-01T00:00:00Z"
+										}
+									]
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"v1",
+									"kind":"Secret",
+									"metadata":{
+										"name":"my-uid",
+										"namespace":"my-namespace",
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"crossplane.io/composite":"my-xr",
+											"servicebinding.io/claim-kind":"Claim",
+											"servicebinding.io/type":"my-type"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"kind\":\"Claim\",\"namespace\":\"my-namespace\",\"name\":\"my-claim\",\"apiVersion\":\"example.org/v1\"}"
+										}
+									},
+									"data":{
+										"password":"dGhlaXItcGFzc3dvcmQ=",
+										"type":"bXktdHlwZQ==",
+										"username":"dGhlaXItdXNlcg=="
+									}
+								}`),
+								Ready: fnv1beta1.Ready_READY_TRUE,
+							},
+							"servicebinding": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"servicebinding.io/v1beta1",
+									"kind":"ServiceBinding",
+									"metadata":{
+										"name":"my-claim",
+										"namespace":"my-namespace",
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"crossplane.io/composite":"my-xr",
+											"servicebinding.io/claim-kind":"Claim"
+										}
+									},
+									"spec":{
+										"service":{
+											"apiVersion":"example.org/v1",
+											"kind":"Claim",
+											"name":"my-claim"
+										},
+										"workload":{
+											"apiVersion":"apps/v1",
+											"kind":"Deployment",
+											"name":"orders"
+										}
+									}
+								}`),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ValidateConnectionSecret": {
+			reason: "The claim's connection secret is validated if it is used as the binding secret",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							RequireWriteConnectionSecretToRef: true,
+							BindingSecretOverrides: map[string]string{
+								"type": "my-type",
+							},
+							BindingSecretTemplates: map[string]string{
+								"uri": "my-type://{{ .host }}",
+							},
+							Validation: &v1alpha1.Validation{
+								Strict: true,
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid"
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									},
+									"writeConnectionSecretToRef":{
+										"name":"my-secret",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  "claim's connection secret is used as the binding secret as is, ignoring bindingSecretOverrides, bindingSecretTemplates, set claimConnectionSecretMode to Enrich to apply them",
+						},
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  "binding does not have a provider entry",
+						},
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_FATAL,
+							Message:  "invalid binding: binding does not have a type entry",
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+		},
+		"KeepObservedSecretName": {
+			reason: "A binding secret whose Object is named after it keeps its name once created, renaming it is reported",
+			args: args{
+				req: &fnv1beta1.RunFunctionRequest{
+					Input: resource.MustStructObject(&v1alpha1.Decorator{
+						Config: v1alpha1.Config{
+							ClaimOverrides: []v1alpha1.ClaimOverride{
+								v1alpha1.ClaimOverrideName,
+								v1alpha1.ClaimOverrideExtraKeys,
+							},
+							BindingSecretOverrides: map[string]string{
+								"type": "mysql",
+							},
+						},
+					}),
+					Observed: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"metadata":{
+									"uid":"my-uid",
+									"annotations":{
+										"binding.servicebinding.io/name":"orders-db",
+										"binding.servicebinding.io/extra-keys":"{\"host\":\"other.example.org\",\"ssl-mode\":\"required\",\"type\":\"postgresql\"}",
+										"binding.servicebinding.io/skip":"true"
+									}
+								},
+								"spec":{
+									"claimRef":{
+										"name":"my-claim",
+										"namespace":"my-namespace"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"name":"my-namespace.my-claim"
+									},
 									"spec":{
 										"forProvider":{
 											"manifest":{
 												"apiVersion":"v1",
 												"kind":"Secret",
 												"metadata":{
-													"name": "my-uid",
-													"creationTimestamp":null
+													"name":"my-claim",
+													"namespace":"my-namespace"
+												}
+											}
+										}
+									}
+								}`),
+							},
+							"database": {
+								ConnectionDetails: map[string][]byte{
+									"host": []byte("db.example.org"),
+								},
+							},
+						},
+					},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+						},
+					},
+				},
+			},
+			want: want{
+				rsp: &fnv1beta1.RunFunctionResponse{
+					Meta: &fnv1beta1.ResponseMeta{Ttl: durationpb.New(response.DefaultTTL)},
+					Desired: &fnv1beta1.State{
+						Composite: &fnv1beta1.Resource{
+							Resource: resource.MustStructJSON(`{
+								"apiVersion":"example.org/v1",
+								"kind":"XR",
+								"status":{
+									"binding":{
+										"name":"my-claim"
+									}
+								}
+							}`),
+						},
+						Resources: map[string]*fnv1beta1.Resource{
+							"bindingsecret": {
+								Resource: resource.MustStructJSON(`{
+									"apiVersion":"kubernetes.crossplane.io/v1alpha1",
+									"kind":"Object",
+									"metadata":{
+										"name":"my-namespace.my-claim",
+										"labels":{
+											"crossplane.io/claim-name":"my-claim",
+											"crossplane.io/claim-namespace":"my-namespace",
+											"servicebinding.io/type":"mysql"
+										},
+										"annotations":{
+											"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+										}
+									},
+									"spec":{
+										"forProvider":{
+											"manifest":{
+												"apiVersion":"v1",
+												"kind":"Secret",
+												"metadata":{
+													"name":"my-claim",
+													"namespace":"my-namespace",
+													"creationTimestamp":null,
+													"labels":{
+														"crossplane.io/claim-name":"my-claim",
+														"crossplane.io/claim-namespace":"my-namespace",
+														"servicebinding.io/type":"mysql"
+													},
+													"annotations":{
+														"servicebinding.io/provisioned-service":"{\"namespace\":\"my-namespace\",\"name\":\"my-claim\"}"
+													}
 												},
 												"data":{
-													"password":"dGhlaXItcGFzc3dvcmQ=",
-													"username":"dGhlaXItdXNlcg==",
-													"type":"bXktZGF0YWJhc2U="
+													"host":"ZGIuZXhhbXBsZS5vcmc=",
+													"ssl-mode":"cmVxdWlyZWQ=",
+													"type":"bXlzcWw="
 												}
 											}
 										},
 										"providerConfigRef":{
-											"name":"my-provider-config"
+											"name":"default"
 										}
 									}
 								}`),
 							},
 						},
 					},
+					Results: []*fnv1beta1.Result{
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  "ignoring annotation \"binding.servicebinding.io/skip\", claims are not allowed to customize the binding's Skip",
+						},
+						{
+							Severity: fnv1beta1.Severity_SEVERITY_WARNING,
+							Message:  `cannot rename binding secret from "my-claim" to "orders-db" after it has been created, the provider-kubernetes Object managing it is named after it`,
+						},
+					},
 				},
 			},
 		},
@@ -204,3 +5058,65 @@ func TestRunFunction(t *testing.T) {
 		})
 	}
 }
+
+func TestBindingSecretNameCollision(t *testing.T) {
+	// binding secrets of claims in the same namespace rendering the same name must be composed as Objects of the same name,
+	// so that Kubernetes rejects the second Object rather than both managing the same secret
+	request := func(uid, claimName string) *fnv1beta1.RunFunctionRequest {
+		return &fnv1beta1.RunFunctionRequest{
+			Input: resource.MustStructObject(&v1alpha1.Decorator{
+				Config: v1alpha1.Config{
+					SecretName: &v1alpha1.SecretName{
+						Strategy: v1alpha1.SecretNameStrategyTemplate,
+						Template: "orders-db",
+					},
+					BindingSecretOverrides: map[string]string{
+						"type": "mysql",
+					},
+				},
+			}),
+			Observed: &fnv1beta1.State{
+				Composite: &fnv1beta1.Resource{
+					Resource: resource.MustStructJSON(fmt.Sprintf(`{
+						"apiVersion":"example.org/v1",
+						"kind":"XR",
+						"metadata":{
+							"uid":%q
+						},
+						"spec":{
+							"claimRef":{
+								"name":%q,
+								"namespace":"my-namespace"
+							}
+						}
+					}`, uid, claimName)),
+				},
+			},
+			Desired: &fnv1beta1.State{
+				Composite: &fnv1beta1.Resource{
+					Resource: resource.MustStructJSON(`{"apiVersion":"example.org/v1","kind":"XR"}`),
+				},
+			},
+		}
+	}
+
+	names := []string{}
+	for _, req := range []*fnv1beta1.RunFunctionRequest{request("first-uid", "first-claim"), request("second-uid", "second-claim")} {
+		f := &Function{log: logging.NewNopLogger()}
+		rsp, err := f.RunFunction(context.Background(), req)
+		if err != nil {
+			t.Fatalf("f.RunFunction(...): %v", err)
+		}
+
+		object, ok := rsp.GetDesired().GetResources()["bindingsecret"]
+		if !ok {
+			t.Fatalf("f.RunFunction(...): binding secret not composed, results: %v", rsp.GetResults())
+		}
+		names = append(names, object.GetResource().GetFields()["metadata"].GetStructValue().GetFields()["name"].GetStringValue())
+	}
+
+	want := []string{"my-namespace.orders-db", "my-namespace.orders-db"}
+	if diff := cmp.Diff(want, names); diff != "" {
+		t.Errorf("names of composed Objects: -want, +got:\n%s", diff)
+	}
+}
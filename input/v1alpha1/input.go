@@ -30,15 +30,649 @@ type Config struct {
 	// spec.writeConnectionSecretToRef or if spec.writeConnectionSecretToRef refers to a different namespace
 	RequireWriteConnectionSecretToRef bool `json:"requireWriteConnectionSecretToRef"`
 
+	// specifies how the binding is published if the claim specifies spec.writeConnectionSecretToRef in its own namespace
+	// if Refer, status.binding.name refers to the claim's connection secret as is
+	// none of the settings shaping the binding secret apply to it, e.g. bindingKeys, overrides, templates, metadataEntries,
+	// secretType, secretName, labels or bindings, a warning lists those specified, while validation applies to its entries
+	// if Enrich, a separate binding secret is composed from the XR's connection details, i.e. the entries of the claim's
+	// connection secret, rather than from the connection details of the composed resources
+	// bindingKeys, overrides and templates are applied to it just like to binding secrets of claims without a connection secret
+	// +kubebuilder:validation:Enum=Refer;Enrich
+	// +kubebuilder:default=Refer
+	// +optional
+	ClaimConnectionSecretMode ClaimConnectionSecretMode `json:"claimConnectionSecretMode,omitempty"`
+
+	// specifies how the binding is published if the XR writes its connection secret to a namespace other than the claim's
+	// if Compose, a binding secret is composed from the connection details just like for claims without a connection secret
+	// if Import, the connection secret is shared with the claim's namespace using Carvel secretgen-controller instead,
+	// a SecretExport is composed in the connection secret's namespace and a SecretImport in the claim's namespace,
+	// the imported secret has the name and the entries of the connection secret and status.binding.name refers to it,
+	// none of the settings of the binding secret apply, e.g. bindingKeys, overrides, templates, secretName or secretType
+	// +kubebuilder:validation:Enum=Compose;Import
+	// +kubebuilder:default=Compose
+	// +optional
+	CrossNamespaceConnectionSecretMode CrossNamespaceConnectionSecretMode `json:"crossNamespaceConnectionSecretMode,omitempty"`
+
+	// specifies the name of the composed resource for the binding secret in the composition pipeline
+	// the composed resource for the ServiceBinding, if any, is named <resourceName>-servicebinding
+	// and the composed resource for the SecretExport, if any, <resourceName>-secretexport
+	// names must be unique within the pipeline, running the decorator in several steps requires a different name per step
+	// defaults to bindingsecret, in which case the composed resources for the ServiceBinding and the SecretExport are named
+	// servicebinding and secretexport
+	// +optional
+	ResourceName string `json:"resourceName,omitempty"`
+
+	// specifies the namespace the binding secret of an XR without a claim is composed in
+	// without a claim, the XR itself is the provisioned service and templates refer to it as claim, e.g. {{.claim.name}}
+	// if not set, XRs without a claim are not bound
+	// +optional
+	TargetNamespace *TargetNamespace `json:"targetNamespace,omitempty"`
+
+	// specifies how the binding secret and the ServiceBinding are composed
+	// if Object, they are composed as manifests of provider-kubernetes Objects, which requires provider-kubernetes to be installed
+	// if Secret, they are composed directly and Crossplane manages them itself, provider-kubernetes is not required
+	// directly composed resources cannot depend on other resources, i.e. dependsOn, detectNamespaceDependency, objectAPI,
+	// objectPolicies and providerConfigRef do not apply and secretDataMode Referenced embeds all values instead
+	// if ExternalSecret, an External Secrets Operator ExternalSecret is composed directly instead of the binding secret,
+	// see externalSecret, the ServiceBinding is composed directly as well
+	// +kubebuilder:validation:Enum=Object;Secret;ExternalSecret
+	// +kubebuilder:default=Object
+	// +optional
+	SecretComposition SecretComposition `json:"secretComposition,omitempty"`
+
+	// specifies the ExternalSecret composed if secretComposition is ExternalSecret
+	// +optional
+	ExternalSecret *ExternalSecret `json:"externalSecret,omitempty"`
+
+	// specifies the secret stores XRs publish their connection details to, see spec.publishConnectionDetailsTo of the XR
+	// if the XR publishes its connection details to one of these stores, values read from connection details the XR
+	// publishes are not embedded in the binding secret, instead they are read from the store, all other values are embedded
+	// values read from connection details the XR does not publish are omitted and reported as warnings
+	// +optional
+	ConnectionDetailsStores []ConnectionDetailsStore `json:"connectionDetailsStores,omitempty"`
+
+	// specifies the provider-kubernetes Object API the binding secret and the ServiceBinding are composed with
+	// if Cluster, cluster scoped Objects of kubernetes.crossplane.io are composed
+	// if Namespaced, Objects of kubernetes.m.crossplane.io are composed in the namespace of the binding secret,
+	// their managementPolicies are derived from objectPolicies.managementPolicy and objectPolicies.deletionPolicy
+	// defaults to Namespaced for namespaced XRs, which cannot compose cluster scoped resources, and to Cluster otherwise
+	// +kubebuilder:validation:Enum=Cluster;Namespaced
+	// +optional
+	ObjectAPI ObjectAPI `json:"objectAPI,omitempty"`
+
 	// specifies the name of the provider config to use when creating the binding secret
-	ProviderConfigRef *ProviderConfigRef `json:"providerConfigRef"`
+	// defaults to default, only used if secretComposition is Object
+	// +optional
+	ProviderConfigRef *ProviderConfigRef `json:"providerConfigRef,omitempty"`
+
+	// specifies the policies of the provider-kubernetes Objects composed for the binding secret and the ServiceBinding
+	// if not set, provider-kubernetes' defaults apply
+	// +optional
+	ObjectPolicies *ObjectPolicies `json:"objectPolicies,omitempty"`
+
+	// specifies resources the binding secret depends on, provider-kubernetes does not create the binding secret before they exist
+	// +optional
+	DependsOn []DependsOn `json:"dependsOn,omitempty"`
+
+	// specifies whether the binding secret depends on its namespace if an earlier step of the pipeline composes
+	// the namespace as a provider-kubernetes Object
+	// +optional
+	DetectNamespaceDependency bool `json:"detectNamespaceDependency,omitempty"`
 
 	// specifies overrides for the binding details
 	BindingSecretOverrides map[string]string `json:"bindingSecretOverrides"`
+
+	// specifies binding entries whose values are rendered from Go templates, e.g. connection URIs
+	// templates are rendered after overrides have been applied and take precedence over them
+	// connection details are available as top-level keys, e.g. {{.username}}, while the observed
+	// XR's metadata, spec and status are available under xr, e.g. {{.xr.metadata.name}}
+	// templates referring to missing keys are skipped and reported as warnings
+	// +optional
+	BindingSecretTemplates map[string]string `json:"bindingSecretTemplates,omitempty"`
+
+	// specifies the order in which the connection details of composed resources are merged
+	// composed resources are referred to by their name in the composition pipeline
+	// if several resources publish the same key, the value of the resource listed first wins
+	// resources not listed here have the lowest precedence and are merged in alphabetical order
+	// +optional
+	ConnectionDetailsPrecedence []string `json:"connectionDetailsPrecedence,omitempty"`
+
+	// specifies which connection details of composed resources end up in the binding
+	// if All, the connection details of all composed resources are merged into the binding
+	// if Mapped, the binding only contains the entries specified in bindingKeys
+	// +kubebuilder:validation:Enum=All;Mapped
+	// +kubebuilder:default=All
+	// +optional
+	ConnectionDetailsMode ConnectionDetailsMode `json:"connectionDetailsMode,omitempty"`
+
+	// specifies binding entries and the connection details or fields they are read from
+	// +optional
+	BindingKeys []BindingKey `json:"bindingKeys,omitempty"`
+
+	// specifies binding entries derived from the XR's metadata, e.g. {type: type, provider: provider}
+	// keys are binding entries, values are the label or annotation keys they are derived from
+	// values are read from the XR's annotations, its labels or the labels of its composition selector, in that order,
+	// the latter allows deriving entries from the labels of the Composition the XR selects, e.g. its type and provider
+	// derived entries win over connection details, while binding keys, overrides and templates win over derived entries
+	// +optional
+	MetadataEntries map[string]string `json:"metadataEntries,omitempty"`
+
+	// specifies how values read from connection details end up in the binding secret
+	// if Embedded, all values are embedded in the manifest of the provider-kubernetes Object
+	// if Referenced, values read from connection details are not embedded, instead provider-kubernetes copies
+	// them into the binding secret from the connection secret of the composed resource they were read from,
+	// or from the XR's connection secret if the composed resource does not write a connection secret itself
+	// values of overrides, templates and field paths are embedded, except for templates reading values of connection details,
+	// i.e. templates referring to such a key or to their data as a whole, e.g. {{ printf "%.4s" .password }} or {{ . }},
+	// these are omitted and reported as warnings as they would embed the values in the manifest
+	// +kubebuilder:validation:Enum=Embedded;Referenced
+	// +kubebuilder:default=Embedded
+	// +optional
+	SecretDataMode SecretDataMode `json:"secretDataMode,omitempty"`
+
+	// specifies the type of the binding secret, e.g. servicebinding.io/mysql
+	// if neither secretType nor deriveSecretType are set, the binding secret is of type Opaque
+	// +optional
+	SecretType string `json:"secretType,omitempty"`
+
+	// specifies whether the type of the binding secret is derived from the binding's type entry as servicebinding.io/<type>
+	// the binding secret is not composed until the binding has a type entry, an already composed binding secret keeps its type
+	// if the type entry disappears, ignored if secretType is set
+	// +optional
+	DeriveSecretType bool `json:"deriveSecretType,omitempty"`
+
+	// specifies whether and how the binding is validated before it is published
+	// if not set, the binding is not validated
+	// +optional
+	Validation *Validation `json:"validation,omitempty"`
+
+	// specifies conditions the binding must meet before status.binding.name is published
+	// until they are met, the XR reports why via its BindingReady condition
+	// if a list of bindings is specified, every binding must meet them before it is listed in status.bindings
+	// once published, status.binding.name and status.bindings entries are not withdrawn if the conditions are no longer met
+	// if the claim's connection secret is used as the binding secret, the connection details of the XR must meet them
+	// if not set, status.binding.name is published right away
+	// +optional
+	PublishConditions *PublishConditions `json:"publishConditions,omitempty"`
+
+	// specifies how the binding secret is named
+	// if not set, the binding secret is named after the XR's UID
+	// +optional
+	SecretName *SecretName `json:"secretName,omitempty"`
+
+	// specifies labels added to the binding secret and its provider-kubernetes Object
+	// in addition to the standard labels identifying the claim, the XR and the binding's type and provider
+	// values are Go templates rendered against the claim and the XR, see secretName.template
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// specifies annotations added to the binding secret and its provider-kubernetes Object
+	// in addition to the standard servicebinding.io/provisioned-service annotation referring to the claim
+	// values are Go templates rendered against the claim and the XR, see secretName.template
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// specifies which settings of the binding claims may customize using annotations
+	// Crossplane propagates the annotations of a claim to its XR, annotations of settings not listed here are ignored
+	// if Name, the binding.servicebinding.io/name annotation specifies the name of the (primary) binding secret
+	// if ExtraKeys, the binding.servicebinding.io/extra-keys annotation specifies additional binding entries as a JSON object,
+	// e.g. {"ssl-mode": "required"}, extra entries have the lowest precedence, they are only set if the binding does not
+	// have them, i.e. they never replace connection details, bindingKeys, metadataEntries or overrides
+	// if Skip, the binding.servicebinding.io/skip annotation set to true skips the binding of the claim altogether
+	// +optional
+	ClaimOverrides []ClaimOverride `json:"claimOverrides,omitempty"`
+
+	// specifies a list of bindings composed instead of a single binding, e.g. for a read-write, a read-only and an admin user
+	// bindings inherit the configuration above, their own settings replace the inherited ones while their overrides and
+	// templates are merged with the inherited ones
+	// every binding has its own binding secret, all of them are listed in status.bindings of the XR while status.binding.name
+	// refers to the binding secret of the primary binding
+	// ignored if the claim's connection secret is referred to as is, see claimConnectionSecretMode
+	// +optional
+	Bindings []Binding `json:"bindings,omitempty"`
+
+	// specifies whether a servicebinding.io ServiceBinding projecting the binding into the claim's workload is composed
+	// the ServiceBinding is composed in the claim's namespace using provider-kubernetes and refers to the claim as its service
+	// its Ready condition and the workload it binds are reflected in status.binding.serviceBinding of the XR
+	// if not set, no ServiceBinding is composed
+	// +optional
+	ServiceBinding *ServiceBinding `json:"serviceBinding,omitempty"`
+}
+
+// Binding specifies one of several bindings of a claim
+type Binding struct {
+	// specifies the name of the binding, must be unique among the bindings of the claim
+	Name string `json:"name"`
+
+	// specifies whether status.binding.name refers to this binding, at most one binding can be the primary binding
+	// defaults to the first binding
+	// +optional
+	Primary bool `json:"primary,omitempty"`
+
+	// specifies which connection details of composed resources end up in the binding, see connectionDetailsMode above
+	// +kubebuilder:validation:Enum=All;Mapped
+	// +optional
+	ConnectionDetailsMode ConnectionDetailsMode `json:"connectionDetailsMode,omitempty"`
+
+	// specifies binding entries and the connection details or fields they are read from, see bindingKeys above
+	// +optional
+	BindingKeys []BindingKey `json:"bindingKeys,omitempty"`
+
+	// specifies overrides for the binding details, merged with the overrides above
+	// +optional
+	BindingSecretOverrides map[string]string `json:"bindingSecretOverrides,omitempty"`
+
+	// specifies binding entries whose values are rendered from Go templates, merged with the templates above
+	// +optional
+	BindingSecretTemplates map[string]string `json:"bindingSecretTemplates,omitempty"`
+
+	// specifies how the binding secret is named, see secretName above
+	// it is not inherited as every binding needs a binding secret of its own
+	// if not set, the binding secret is named after the XR's UID, followed by -<name>
+	// +optional
+	SecretName *SecretName `json:"secretName,omitempty"`
+
+	// specifies the name of the composed resource for the binding secret in the composition pipeline
+	// defaults to the resource name above, followed by -<name>
+	// +optional
+	ResourceName string `json:"resourceName,omitempty"`
+
+	// specifies an additional field of the XR the name of the binding secret is published to, e.g. status.adminBinding.name
+	// +optional
+	StatusFieldPath string `json:"statusFieldPath,omitempty"`
+}
+
+// ServiceBinding specifies the servicebinding.io ServiceBinding composed for the claim's workload
+// the ServiceBinding is only composed once the claim specifies its workload
+type ServiceBinding struct {
+	// specifies the Go template the name of the ServiceBinding is rendered from, see secretName.template
+	// defaults to the name of the claim
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// specifies the name of the binding as projected into the workload, i.e. the ServiceBinding's spec.name
+	// defaults to the name of the ServiceBinding
+	// +optional
+	BindingName string `json:"bindingName,omitempty"`
+
+	// specifies the workload the binding is projected into
+	Workload Workload `json:"workload"`
+
+	// specifies environment variables of the workload that binding entries are projected into
+	// +optional
+	Env []EnvMapping `json:"env,omitempty"`
+}
+
+// Workload specifies the workload a binding is projected into
+// the workload's name or label selector is read from an annotation or a field of the XR
+type Workload struct {
+	// specifies the apiVersion of the workload
+	// +kubebuilder:default=apps/v1
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// specifies the kind of the workload
+	// +kubebuilder:default=Deployment
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// specifies the annotation of the XR the workload is read from, e.g. example.org/workload
+	// Crossplane propagates the annotations of a claim to its XR, this allows claims to specify their workload
+	// +optional
+	Annotation string `json:"annotation,omitempty"`
+
+	// specifies the field path of the observed XR the workload is read from, e.g. spec.parameters.workload
+	// ignored if the XR has the annotation
+	// +optional
+	FieldPath string `json:"fieldPath,omitempty"`
+
+	// specifies how the value read from the annotation or field path refers to the workload
+	// if Name, the value is the name of the workload
+	// if Selector, the value is a label selector matching the workload, e.g. app=orders,tier=backend
+	// +kubebuilder:validation:Enum=Name;Selector
+	// +kubebuilder:default=Name
+	// +optional
+	ReferenceType WorkloadReferenceType `json:"referenceType,omitempty"`
+}
+
+// WorkloadReferenceType specifies how a workload is referred to
+type WorkloadReferenceType string
+
+const (
+	// WorkloadReferenceTypeName refers to the workload by its name
+	WorkloadReferenceTypeName WorkloadReferenceType = "Name"
+
+	// WorkloadReferenceTypeSelector refers to the workload by a label selector
+	WorkloadReferenceTypeSelector WorkloadReferenceType = "Selector"
+)
+
+// EnvMapping specifies an environment variable a binding entry is projected into
+type EnvMapping struct {
+	// specifies the name of the environment variable
+	Name string `json:"name"`
+
+	// specifies the binding entry whose value is projected into the environment variable
+	Key string `json:"key"`
+}
+
+// SecretName specifies how the binding secret is named
+// unless the binding secret is named after the XR's UID, the provider-kubernetes Object is named
+// <namespace>.<secret name> so that two claims rendering the same name cannot both compose it
+// as the Object cannot be renamed, such a binding secret keeps its name once created, a warning is reported if the
+// rendered name changes later on, e.g. because of the binding.servicebinding.io/name annotation of the claim
+type SecretName struct {
+	// specifies the naming strategy
+	// if UID, the binding secret is named after the XR's UID, followed by the optional suffix
+	// if ClaimName, the binding secret is named after the claim, followed by the optional suffix
+	// if Template, the binding secret is named after the rendered template
+	// +kubebuilder:validation:Enum=UID;ClaimName;Template
+	// +kubebuilder:default=UID
+	// +optional
+	Strategy SecretNameStrategy `json:"strategy,omitempty"`
+
+	// specifies the suffix appended to the claim name or the XR's UID, e.g. -binding
+	// +optional
+	Suffix string `json:"suffix,omitempty"`
+
+	// specifies the Go template the name is rendered from
+	// the claim's apiVersion, kind, name and namespace are available under claim, e.g. {{.claim.name}},
+	// while the observed XR's metadata, spec and status are available under xr, e.g. {{.xr.metadata.name}}
+	// +optional
+	Template string `json:"template,omitempty"`
+}
+
+// SecretNameStrategy specifies how the binding secret is named
+type SecretNameStrategy string
+
+const (
+	// SecretNameStrategyUID names the binding secret after the XR's UID, followed by an optional suffix
+	SecretNameStrategyUID SecretNameStrategy = "UID"
+
+	// SecretNameStrategyClaimName names the binding secret after the claim, followed by an optional suffix
+	SecretNameStrategyClaimName SecretNameStrategy = "ClaimName"
+
+	// SecretNameStrategyTemplate names the binding secret after a rendered Go template
+	SecretNameStrategyTemplate SecretNameStrategy = "Template"
+)
+
+// PublishConditions specifies conditions the binding must meet before status.binding.name is published
+// the binding must always have a type entry and, for well-known types, the entries required for its type
+type PublishConditions struct {
+	// specifies additional entries the binding must have
+	// +optional
+	RequiredKeys []string `json:"requiredKeys,omitempty"`
+
+	// specifies whether composed resources must be ready
+	// +optional
+	RequireReadyResources bool `json:"requireReadyResources,omitempty"`
+
+	// specifies the names of the composed resources that must be ready
+	// defaults to all observed composed resources except secrets, which do not report whether they are ready, and the
+	// resources composed by this function, i.e. the binding secrets, the ServiceBinding and the SecretExport and SecretImport,
+	// ignored unless requireReadyResources is true
+	// +optional
+	ReadyResources []string `json:"readyResources,omitempty"`
+}
+
+// Validation specifies how the binding is validated before it is published
+// the binding must have a type entry and should have a provider entry, bindings of well-known types,
+// e.g. mysql, postgresql, redis, mongodb, kafka, rabbitmq or ldap, must also have the entries required for their type
+// and should have the optional entries of their type, missing optional entries are reported as warnings only
+type Validation struct {
+	// specifies whether violations are reported as fatal results, stopping the pipeline, rather than warnings
+	// +optional
+	Strict bool `json:"strict,omitempty"`
+}
+
+// TargetNamespace specifies the namespace the binding secret of an XR without a claim is composed in
+// the namespace is read from the annotation of the XR, its field or the static name, in that order
+type TargetNamespace struct {
+	// specifies the name of the namespace
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// specifies the annotation of the XR the namespace is read from, e.g. example.org/binding-namespace
+	// +optional
+	Annotation string `json:"annotation,omitempty"`
+
+	// specifies the field path of the observed XR the namespace is read from, e.g. spec.parameters.bindingNamespace
+	// +optional
+	FieldPath string `json:"fieldPath,omitempty"`
+}
+
+// ClaimOverride specifies a setting of the binding claims may customize using annotations
+// +kubebuilder:validation:Enum=Name;ExtraKeys;Skip
+type ClaimOverride string
+
+const (
+	// ClaimOverrideName allows claims to specify the name of the binding secret
+	ClaimOverrideName ClaimOverride = "Name"
+
+	// ClaimOverrideExtraKeys allows claims to specify additional binding entries
+	ClaimOverrideExtraKeys ClaimOverride = "ExtraKeys"
+
+	// ClaimOverrideSkip allows claims to skip the binding
+	ClaimOverrideSkip ClaimOverride = "Skip"
+)
+
+// ClaimConnectionSecretMode specifies how the binding is published if the claim specifies its own connection secret
+type ClaimConnectionSecretMode string
+
+const (
+	// ClaimConnectionSecretModeRefer refers to the claim's connection secret as is
+	ClaimConnectionSecretModeRefer ClaimConnectionSecretMode = "Refer"
+
+	// ClaimConnectionSecretModeEnrich composes a separate binding secret from the entries of the claim's connection secret
+	ClaimConnectionSecretModeEnrich ClaimConnectionSecretMode = "Enrich"
+)
+
+// CrossNamespaceConnectionSecretMode specifies how the binding is published if the XR's connection secret is in another namespace
+type CrossNamespaceConnectionSecretMode string
+
+const (
+	// CrossNamespaceConnectionSecretModeCompose composes a binding secret from the connection details
+	CrossNamespaceConnectionSecretModeCompose CrossNamespaceConnectionSecretMode = "Compose"
+
+	// CrossNamespaceConnectionSecretModeImport imports the connection secret into the claim's namespace using secretgen-controller
+	CrossNamespaceConnectionSecretModeImport CrossNamespaceConnectionSecretMode = "Import"
+)
+
+// SecretComposition specifies how the binding secret and the ServiceBinding are composed
+type SecretComposition string
+
+const (
+	// SecretCompositionObject composes the binding secret and the ServiceBinding as manifests of provider-kubernetes Objects
+	SecretCompositionObject SecretComposition = "Object"
+
+	// SecretCompositionSecret composes the binding secret and the ServiceBinding directly
+	SecretCompositionSecret SecretComposition = "Secret"
+
+	// SecretCompositionExternalSecret composes an ExternalSecret for the binding secret and the ServiceBinding directly
+	SecretCompositionExternalSecret SecretComposition = "ExternalSecret"
+)
+
+// ExternalSecret specifies the External Secrets Operator ExternalSecret composed for the binding secret
+// the ExternalSecret is named after the binding secret and creates it as its target, following the binding secret's
+// name, type, labels and annotations, values read from connection details are read from the remote key in the secret
+// store rather than embedded, templates reading them are rendered by the ExternalSecret rather than embedded rendered,
+// all other values, e.g. overrides and other templates, are merged into the binding secret
+type ExternalSecret struct {
+	// specifies the SecretStore the binding is read from
+	SecretStoreRef SecretStoreRef `json:"secretStoreRef"`
+
+	// specifies the Go template the remote key of the binding in the secret store is rendered from, see secretName.template
+	// e.g. bindings/{{.claim.namespace}}/{{.claim.name}}, every binding entry read from a connection detail is read from
+	// the property of the remote key named after the connection detail, i.e. its connectionDetailKey
+	RemoteKey string `json:"remoteKey"`
+
+	// specifies the interval the ExternalSecret refreshes the binding secret at, e.g. 1h
+	// defaults to the External Secrets Operator's default
+	// +optional
+	RefreshInterval string `json:"refreshInterval,omitempty"`
+}
+
+// ConnectionDetailsStore specifies a secret store XRs publish their connection details to and how the binding reads them
+// exactly one of namespace and secretStoreRef must be specified
+type ConnectionDetailsStore struct {
+	// specifies the name of the StoreConfig, i.e. spec.publishConnectionDetailsTo.configRef.name of the XR
+	// +kubebuilder:default=default
+	// +optional
+	StoreConfig string `json:"storeConfig,omitempty"`
+
+	// specifies the namespace of the secrets a Kubernetes StoreConfig publishes connection details to, i.e. its defaultScope
+	// if set, provider-kubernetes copies the values from the published secret into the binding secret,
+	// this requires secretComposition Object
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// specifies the SecretStore of External Secrets Operator reading the same store as the StoreConfig
+	// if set, an ExternalSecret reading the values from the store is composed for the binding secret regardless of
+	// secretComposition, its refresh interval is the one of externalSecret, if any
+	// +optional
+	SecretStoreRef *SecretStoreRef `json:"secretStoreRef,omitempty"`
+
+	// specifies the prefix of the remote key the ExternalSecret reads, the remote key is the prefix followed by the name
+	// the XR publishes its connection details under, e.g. crossplane-system/ for a Vault StoreConfig whose parent path
+	// is crossplane-system
+	// +optional
+	KeyPrefix string `json:"keyPrefix,omitempty"`
+}
+
+// SecretStoreRef specifies the SecretStore of an ExternalSecret
+type SecretStoreRef struct {
+	// specifies the name of the SecretStore
+	Name string `json:"name"`
+
+	// specifies the kind of the SecretStore
+	// +kubebuilder:validation:Enum=SecretStore;ClusterSecretStore
+	// +kubebuilder:default=SecretStore
+	// +optional
+	Kind string `json:"kind,omitempty"`
 }
 
+// SecretDataMode specifies how values read from connection details end up in the binding secret
+type SecretDataMode string
+
+const (
+	// SecretDataModeEmbedded embeds all values in the manifest of the provider-kubernetes Object
+	SecretDataModeEmbedded SecretDataMode = "Embedded"
+
+	// SecretDataModeReferenced copies values read from connection details from the connection secrets they were published to
+	SecretDataModeReferenced SecretDataMode = "Referenced"
+)
+
+// ConnectionDetailsMode specifies which connection details of composed resources end up in the binding
+type ConnectionDetailsMode string
+
+const (
+	// ConnectionDetailsModeAll merges the connection details of all composed resources into the binding
+	ConnectionDetailsModeAll ConnectionDetailsMode = "All"
+
+	// ConnectionDetailsModeMapped only adds the entries specified in bindingKeys to the binding
+	ConnectionDetailsModeMapped ConnectionDetailsMode = "Mapped"
+)
+
+// BindingKey specifies a single binding entry and where its value is read from
+type BindingKey struct {
+	// specifies the name of the entry in the binding secret
+	Name string `json:"name"`
+
+	// specifies the type of source the value is read from
+	// +kubebuilder:validation:Enum=FromConnectionDetail;FromCompositeFieldPath;FromComposedFieldPath
+	// +kubebuilder:default=FromConnectionDetail
+	// +optional
+	Type BindingKeySourceType `json:"type,omitempty"`
+
+	// specifies the name of the composed resource in the composition pipeline to read the value from
+	// required for sources of type FromConnectionDetail and FromComposedFieldPath
+	// +optional
+	ResourceName string `json:"resourceName,omitempty"`
+
+	// specifies the connection detail key of the composed resource to read the value from
+	// defaults to the name of the binding entry
+	// +optional
+	ConnectionDetailKey string `json:"connectionDetailKey,omitempty"`
+
+	// specifies the field path to read the value from, e.g. status.atProvider.endpoint
+	// required for sources of type FromCompositeFieldPath and FromComposedFieldPath
+	// +optional
+	FieldPath string `json:"fieldPath,omitempty"`
+}
+
+// BindingKeySourceType specifies the type of source a binding entry is read from
+type BindingKeySourceType string
+
+const (
+	// BindingKeySourceFromConnectionDetail reads the value from a connection detail of an observed composed resource
+	BindingKeySourceFromConnectionDetail BindingKeySourceType = "FromConnectionDetail"
+
+	// BindingKeySourceFromCompositeFieldPath reads the value from a field of the observed XR
+	BindingKeySourceFromCompositeFieldPath BindingKeySourceType = "FromCompositeFieldPath"
+
+	// BindingKeySourceFromComposedFieldPath reads the value from a field of an observed composed resource
+	BindingKeySourceFromComposedFieldPath BindingKeySourceType = "FromComposedFieldPath"
+)
+
+// DependsOn specifies a resource the binding secret depends on
+// name and namespace are Go templates rendered against the claim and the XR, see secretName.template
+type DependsOn struct {
+	// specifies the apiVersion of the resource
+	// +kubebuilder:default=kubernetes.crossplane.io/v1alpha1
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// specifies the kind of the resource
+	// +kubebuilder:default=Object
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// specifies the name of the resource
+	Name string `json:"name"`
+
+	// specifies the namespace of the resource, if it is namespaced
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// ObjectPolicies specifies the policies of provider-kubernetes Objects
+type ObjectPolicies struct {
+	// specifies what provider-kubernetes is allowed to do with the manifest of an Object
+	// e.g. ObserveCreateUpdate keeps the binding secret when the claim is deleted so that running workloads keep their credentials
+	// +kubebuilder:validation:Enum=Default;ObserveCreateUpdate;ObserveDelete;Observe
+	// +optional
+	ManagementPolicy string `json:"managementPolicy,omitempty"`
+
+	// specifies how the readiness of an Object is determined
+	// if DeriveFromObject, an Object is only ready once the resource in its manifest is ready
+	// +kubebuilder:validation:Enum=SuccessfulCreate;DeriveFromObject
+	// +optional
+	ReadinessPolicy string `json:"readinessPolicy,omitempty"`
+
+	// specifies what happens to the resource in the manifest of an Object when the Object is deleted
+	// +kubebuilder:validation:Enum=Orphan;Delete
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+}
+
+// ObjectAPI specifies the provider-kubernetes Object API
+type ObjectAPI string
+
+const (
+	// ObjectAPICluster composes cluster scoped Objects of kubernetes.crossplane.io
+	ObjectAPICluster ObjectAPI = "Cluster"
+
+	// ObjectAPINamespaced composes namespaced Objects of kubernetes.m.crossplane.io
+	ObjectAPINamespaced ObjectAPI = "Namespaced"
+)
+
 // ProviderConfigRef specifies the provider config to use when creating the binding secret
 type ProviderConfigRef struct {
 	// specifies the name of the provider config to use when creating the binding secret
 	Name string `json:"name"`
+
+	// specifies the kind of the provider config, only used by namespaced Objects
+	// +kubebuilder:validation:Enum=ProviderConfig;ClusterProviderConfig
+	// +kubebuilder:default=ClusterProviderConfig
+	// +optional
+	Kind string `json:"kind,omitempty"`
 }
@@ -8,14 +8,95 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Binding) DeepCopyInto(out *Binding) {
+	*out = *in
+	if in.BindingKeys != nil {
+		in, out := &in.BindingKeys, &out.BindingKeys
+		*out = make([]BindingKey, len(*in))
+		copy(*out, *in)
+	}
+	if in.BindingSecretOverrides != nil {
+		in, out := &in.BindingSecretOverrides, &out.BindingSecretOverrides
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.BindingSecretTemplates != nil {
+		in, out := &in.BindingSecretTemplates, &out.BindingSecretTemplates
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SecretName != nil {
+		in, out := &in.SecretName, &out.SecretName
+		*out = new(SecretName)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Binding.
+func (in *Binding) DeepCopy() *Binding {
+	if in == nil {
+		return nil
+	}
+	out := new(Binding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BindingKey) DeepCopyInto(out *BindingKey) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BindingKey.
+func (in *BindingKey) DeepCopy() *BindingKey {
+	if in == nil {
+		return nil
+	}
+	out := new(BindingKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Config) DeepCopyInto(out *Config) {
 	*out = *in
+	if in.TargetNamespace != nil {
+		in, out := &in.TargetNamespace, &out.TargetNamespace
+		*out = new(TargetNamespace)
+		**out = **in
+	}
+	if in.ExternalSecret != nil {
+		in, out := &in.ExternalSecret, &out.ExternalSecret
+		*out = new(ExternalSecret)
+		**out = **in
+	}
+	if in.ConnectionDetailsStores != nil {
+		in, out := &in.ConnectionDetailsStores, &out.ConnectionDetailsStores
+		*out = make([]ConnectionDetailsStore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ProviderConfigRef != nil {
 		in, out := &in.ProviderConfigRef, &out.ProviderConfigRef
 		*out = new(ProviderConfigRef)
 		**out = **in
 	}
+	if in.ObjectPolicies != nil {
+		in, out := &in.ObjectPolicies, &out.ObjectPolicies
+		*out = new(ObjectPolicies)
+		**out = **in
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]DependsOn, len(*in))
+		copy(*out, *in)
+	}
 	if in.BindingSecretOverrides != nil {
 		in, out := &in.BindingSecretOverrides, &out.BindingSecretOverrides
 		*out = make(map[string]string, len(*in))
@@ -23,6 +104,76 @@ func (in *Config) DeepCopyInto(out *Config) {
 			(*out)[key] = val
 		}
 	}
+	if in.BindingSecretTemplates != nil {
+		in, out := &in.BindingSecretTemplates, &out.BindingSecretTemplates
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ConnectionDetailsPrecedence != nil {
+		in, out := &in.ConnectionDetailsPrecedence, &out.ConnectionDetailsPrecedence
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BindingKeys != nil {
+		in, out := &in.BindingKeys, &out.BindingKeys
+		*out = make([]BindingKey, len(*in))
+		copy(*out, *in)
+	}
+	if in.MetadataEntries != nil {
+		in, out := &in.MetadataEntries, &out.MetadataEntries
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Validation != nil {
+		in, out := &in.Validation, &out.Validation
+		*out = new(Validation)
+		**out = **in
+	}
+	if in.PublishConditions != nil {
+		in, out := &in.PublishConditions, &out.PublishConditions
+		*out = new(PublishConditions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SecretName != nil {
+		in, out := &in.SecretName, &out.SecretName
+		*out = new(SecretName)
+		**out = **in
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ClaimOverrides != nil {
+		in, out := &in.ClaimOverrides, &out.ClaimOverrides
+		*out = make([]ClaimOverride, len(*in))
+		copy(*out, *in)
+	}
+	if in.Bindings != nil {
+		in, out := &in.Bindings, &out.Bindings
+		*out = make([]Binding, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ServiceBinding != nil {
+		in, out := &in.ServiceBinding, &out.ServiceBinding
+		*out = new(ServiceBinding)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Config.
@@ -35,6 +186,26 @@ func (in *Config) DeepCopy() *Config {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionDetailsStore) DeepCopyInto(out *ConnectionDetailsStore) {
+	*out = *in
+	if in.SecretStoreRef != nil {
+		in, out := &in.SecretStoreRef, &out.SecretStoreRef
+		*out = new(SecretStoreRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionDetailsStore.
+func (in *ConnectionDetailsStore) DeepCopy() *ConnectionDetailsStore {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionDetailsStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Decorator) DeepCopyInto(out *Decorator) {
 	*out = *in
@@ -61,6 +232,67 @@ func (in *Decorator) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DependsOn) DeepCopyInto(out *DependsOn) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DependsOn.
+func (in *DependsOn) DeepCopy() *DependsOn {
+	if in == nil {
+		return nil
+	}
+	out := new(DependsOn)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EnvMapping) DeepCopyInto(out *EnvMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EnvMapping.
+func (in *EnvMapping) DeepCopy() *EnvMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(EnvMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExternalSecret) DeepCopyInto(out *ExternalSecret) {
+	*out = *in
+	out.SecretStoreRef = in.SecretStoreRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExternalSecret.
+func (in *ExternalSecret) DeepCopy() *ExternalSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(ExternalSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectPolicies) DeepCopyInto(out *ObjectPolicies) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectPolicies.
+func (in *ObjectPolicies) DeepCopy() *ObjectPolicies {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectPolicies)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProviderConfigRef) DeepCopyInto(out *ProviderConfigRef) {
 	*out = *in
@@ -75,3 +307,124 @@ func (in *ProviderConfigRef) DeepCopy() *ProviderConfigRef {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PublishConditions) DeepCopyInto(out *PublishConditions) {
+	*out = *in
+	if in.RequiredKeys != nil {
+		in, out := &in.RequiredKeys, &out.RequiredKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ReadyResources != nil {
+		in, out := &in.ReadyResources, &out.ReadyResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PublishConditions.
+func (in *PublishConditions) DeepCopy() *PublishConditions {
+	if in == nil {
+		return nil
+	}
+	out := new(PublishConditions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretName) DeepCopyInto(out *SecretName) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretName.
+func (in *SecretName) DeepCopy() *SecretName {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretName)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretStoreRef) DeepCopyInto(out *SecretStoreRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretStoreRef.
+func (in *SecretStoreRef) DeepCopy() *SecretStoreRef {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretStoreRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceBinding) DeepCopyInto(out *ServiceBinding) {
+	*out = *in
+	out.Workload = in.Workload
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]EnvMapping, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceBinding.
+func (in *ServiceBinding) DeepCopy() *ServiceBinding {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceBinding)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TargetNamespace) DeepCopyInto(out *TargetNamespace) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetNamespace.
+func (in *TargetNamespace) DeepCopy() *TargetNamespace {
+	if in == nil {
+		return nil
+	}
+	out := new(TargetNamespace)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Validation) DeepCopyInto(out *Validation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Validation.
+func (in *Validation) DeepCopy() *Validation {
+	if in == nil {
+		return nil
+	}
+	out := new(Validation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Workload) DeepCopyInto(out *Workload) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Workload.
+func (in *Workload) DeepCopy() *Workload {
+	if in == nil {
+		return nil
+	}
+	out := new(Workload)
+	in.DeepCopyInto(out)
+	return out
+}
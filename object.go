@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	providerv1alpha1 "github.com/crossplane-contrib/provider-kubernetes/apis/object/v1alpha1"
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
+	"github.com/crossplane/function-sdk-go/resource"
+	"github.com/crossplane/function-sdk-go/resource/composed"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/st3v/servicebinding-decorator/input/v1alpha1"
+)
+
+// newObject returns a provider-kubernetes Object for the supplied manifest using the configured provider config and policies
+func newObject(cfg v1alpha1.Config, manifest []byte, references []providerv1alpha1.Reference) *providerv1alpha1.Object {
+	providerConfigName := "default"
+	if cfg.ProviderConfigRef != nil && cfg.ProviderConfigRef.Name != "" {
+		providerConfigName = cfg.ProviderConfigRef.Name
+	}
+
+	object := &providerv1alpha1.Object{
+		Spec: providerv1alpha1.ObjectSpec{
+			ForProvider: providerv1alpha1.ObjectParameters{
+				Manifest: runtime.RawExtension{
+					Raw: manifest,
+				},
+			},
+			References: references,
+			ResourceSpec: providerv1alpha1.ResourceSpec{
+				ProviderConfigReference: &xpv1.Reference{
+					Name: providerConfigName,
+				},
+			},
+		},
+	}
+
+	if p := cfg.ObjectPolicies; p != nil {
+		object.Spec.ManagementPolicy = providerv1alpha1.ManagementPolicy(p.ManagementPolicy)
+		object.Spec.Readiness.Policy = providerv1alpha1.ReadinessPolicy(p.ReadinessPolicy)
+		object.Spec.DeletionPolicy = xpv1.DeletionPolicy(p.DeletionPolicy)
+	}
+
+	return object
+}
+
+// namespacedObjectAPIVersion is the apiVersion of namespaced provider-kubernetes Objects
+const namespacedObjectAPIVersion = "kubernetes.m.crossplane.io/v1alpha1"
+
+// managementPoliciesOf maps the management policies of cluster scoped Objects to the management policies of namespaced Objects
+var managementPoliciesOf = map[providerv1alpha1.ManagementPolicy][]string{
+	providerv1alpha1.Default:             {"*"},
+	providerv1alpha1.ObserveCreateUpdate: {"Observe", "Create", "Update", "LateInitialize"},
+	providerv1alpha1.ObserveDelete:       {"Observe", "Delete"},
+	providerv1alpha1.Observe:             {"Observe"},
+}
+
+// composeObject returns the composed provider-kubernetes Object for the supplied manifest
+// the Object is namespaced and composed in the supplied namespace if the configuration or the XR require namespaced Objects
+func composeObject(cfg v1alpha1.Config, xr *resource.Composite, manifest []byte, references []providerv1alpha1.Reference, namespace string) (*composed.Unstructured, error) {
+	object := newObject(cfg, manifest, references)
+
+	cd, err := composed.From(object)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot get composed resource from %T", object)
+	}
+
+	if !useNamespacedObjects(cfg, xr) {
+		return cd, nil
+	}
+
+	return cd, errors.Wrap(toNamespacedObject(cd, cfg, namespace), "cannot convert Object to namespaced Object")
+}
+
+// composeManifest returns the composed resource for the supplied manifest
+// this is either the manifest itself, if it is composed directly, or the provider-kubernetes Object managing it,
+// which is labeled with the supplied labels
+func composeManifest(cfg v1alpha1.Config, xr *resource.Composite, manifest map[string]any, labels map[string]string) (*composed.Unstructured, error) {
+	raw, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot encode manifest")
+	}
+
+	cd := composed.New()
+	if err := json.Unmarshal(raw, &cd.Object); err != nil {
+		return nil, errors.Wrap(err, "cannot decode manifest")
+	}
+
+	if composesDirectly(cfg) {
+		return cd, nil
+	}
+
+	object, err := composeObject(cfg, xr, raw, nil, cd.GetNamespace())
+	if err != nil {
+		return nil, err
+	}
+
+	object.SetLabels(labels)
+
+	return object, nil
+}
+
+// useNamespacedObjects returns whether Objects are composed using the namespaced Object API
+func useNamespacedObjects(cfg v1alpha1.Config, xr *resource.Composite) bool {
+	switch cfg.ObjectAPI {
+	case v1alpha1.ObjectAPINamespaced:
+		return true
+	case v1alpha1.ObjectAPICluster:
+		return false
+	}
+
+	// namespaced XRs cannot compose cluster scoped resources
+	return xr.Resource.GetNamespace() != ""
+}
+
+// toNamespacedObject converts the supplied cluster scoped Object to a namespaced Object in the supplied namespace
+// namespaced Objects refer to their provider config by kind and name and do not support management and deletion policies,
+// these are converted to management policies instead
+func toNamespacedObject(cd *composed.Unstructured, cfg v1alpha1.Config, namespace string) error {
+	cd.SetAPIVersion(namespacedObjectAPIVersion)
+	cd.SetNamespace(namespace)
+
+	kind := "ClusterProviderConfig"
+	if cfg.ProviderConfigRef != nil && cfg.ProviderConfigRef.Kind != "" {
+		kind = cfg.ProviderConfigRef.Kind
+	}
+	if err := cd.SetString("spec.providerConfigRef.kind", kind); err != nil {
+		return err
+	}
+
+	managementPolicy, _ := cd.GetString("spec.managementPolicy")
+	deletionPolicy, _ := cd.GetString("spec.deletionPolicy")
+	unstructured.RemoveNestedField(cd.Object, "spec", "managementPolicy")
+	unstructured.RemoveNestedField(cd.Object, "spec", "deletionPolicy")
+
+	if managementPolicy == "" && deletionPolicy == "" {
+		return nil
+	}
+
+	policies := managementPoliciesOf[providerv1alpha1.ManagementPolicy(managementPolicy)]
+	if managementPolicy == "" {
+		policies = managementPoliciesOf[providerv1alpha1.Default]
+	}
+
+	// orphaned resources are not deleted, i.e. their Objects must not be allowed to delete them
+	if xpv1.DeletionPolicy(deletionPolicy) == xpv1.DeletionOrphan {
+		if len(policies) == 1 && policies[0] == "*" {
+			policies = managementPoliciesOf[providerv1alpha1.ObserveCreateUpdate]
+		}
+
+		kept := []string{}
+		for _, p := range policies {
+			if p != "Delete" {
+				kept = append(kept, p)
+			}
+		}
+		policies = kept
+	}
+
+	values := make([]any, 0, len(policies))
+	for _, p := range policies {
+		values = append(values, p)
+	}
+	return cd.SetValue("spec.managementPolicies", values)
+}
+
+// referenceSecretData splits details into the data to embed in the binding secret and provider-kubernetes
+// references that copy all entries read from connection details from the connection secrets they were published to
+// published refers to the secret the XR publishes its connection details to in a secret store, if any
+// entries that cannot be referenced are omitted rather than embedded and reported as warnings, this includes
+// entries rendered from templates reading values of connection details as these would embed the values
+func referenceSecretData(details *bindingDetails, xr *resource.Composite, observed map[resource.Name]resource.ObservedComposed, published *xpv1.SecretReference) (map[string][]byte, []providerv1alpha1.Reference, []error) {
+	data := map[string][]byte{}
+	references := []providerv1alpha1.Reference{}
+	warnings := []error{}
+
+	keys := make([]string, 0, len(details.data))
+	for k := range details.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if details.sensitive[k] {
+			warnings = append(warnings, errors.Errorf("cannot embed binding key %q, its template reads values of connection details which must not be embedded in the binding secret's manifest", k))
+			continue
+		}
+
+		src, ok := details.sources[k]
+		if !ok {
+			data[k] = details.data[k]
+			continue
+		}
+
+		ref, key := connectionSecretOf(src, details.data[k], xr, observed, published)
+		if ref == nil {
+			warnings = append(warnings, errors.Errorf("cannot reference connection detail %q of composed resource %q for binding key %q, neither the composed resource nor the composite resource write it to a connection secret", src.key, src.resource, k))
+			continue
+		}
+
+		fromFieldPath, toFieldPath := secretDataFieldPath(key), secretDataFieldPath(k)
+		references = append(references, providerv1alpha1.Reference{
+			PatchesFrom: &providerv1alpha1.PatchesFrom{
+				DependsOn: providerv1alpha1.DependsOn{
+					APIVersion: "v1",
+					Kind:       "Secret",
+					Name:       ref.Name,
+					Namespace:  ref.Namespace,
+				},
+				FieldPath: &fromFieldPath,
+			},
+			ToFieldPath: &toFieldPath,
+		})
+	}
+
+	return data, references, warnings
+}
+
+// connectionSecretOf returns the connection secret, and the key within it, that the supplied connection detail was published to
+// this is the connection secret of the composed resource itself or, if it doesn't write one, the XR's connection secret
+// or the supplied secret the XR publishes its connection details to, provided the XR publishes the same key with the same value
+func connectionSecretOf(src connectionDetailRef, value []byte, xr *resource.Composite, observed map[resource.Name]resource.ObservedComposed, published *xpv1.SecretReference) (*xpv1.SecretReference, string) {
+	if ocr, ok := observed[src.resource]; ok {
+		if ref := ocr.Resource.GetWriteConnectionSecretToReference(); ref != nil && ref.Name != "" {
+			return ref, src.key
+		}
+	}
+
+	if !publishesConnectionDetail(xr, src.key, value) {
+		return nil, ""
+	}
+
+	if ref := xr.Resource.GetWriteConnectionSecretToReference(); ref != nil && ref.Name != "" {
+		return ref, src.key
+	}
+
+	if published != nil {
+		return published, src.key
+	}
+
+	return nil, ""
+}
+
+// publishesConnectionDetail returns whether the XR publishes the supplied key with the supplied value
+func publishesConnectionDetail(xr *resource.Composite, key string, value []byte) bool {
+	v, ok := xr.ConnectionDetails[key]
+	return ok && bytes.Equal(v, value)
+}
+
+// bindingDependencies returns references declaring the dependencies of the binding secret in the supplied namespace
+// these are the configured dependencies followed by the namespace, if detected among the desired composed resources
+// dependencies whose name or namespace cannot be rendered are skipped and reported as warnings
+func bindingDependencies(cfg v1alpha1.Config, namespace string, xr *resource.Composite, c *claim.Reference, desired map[resource.Name]*resource.DesiredComposed) ([]providerv1alpha1.Reference, []error) {
+	references := []providerv1alpha1.Reference{}
+	warnings := []error{}
+
+	for i, d := range cfg.DependsOn {
+		name, err := renderMetadataTemplate("dependsOn.name", d.Name, xr, c)
+		if err != nil {
+			warnings = append(warnings, errors.Wrapf(err, "cannot render name of dependency %d", i))
+			continue
+		}
+
+		ns, err := renderMetadataTemplate("dependsOn.namespace", d.Namespace, xr, c)
+		if err != nil {
+			warnings = append(warnings, errors.Wrapf(err, "cannot render namespace of dependency %d", i))
+			continue
+		}
+
+		references = append(references, providerv1alpha1.Reference{
+			DependsOn: &providerv1alpha1.DependsOn{
+				APIVersion: d.APIVersion,
+				Kind:       d.Kind,
+				Name:       name,
+				Namespace:  ns,
+			},
+		})
+	}
+
+	if cfg.DetectNamespaceDependency && composesNamespace(desired, namespace) {
+		references = append(references, providerv1alpha1.Reference{
+			DependsOn: &providerv1alpha1.DependsOn{
+				APIVersion: "v1",
+				Kind:       "Namespace",
+				Name:       namespace,
+			},
+		})
+	}
+
+	return references, warnings
+}
+
+// composesNamespace returns whether the supplied desired composed resources include a provider-kubernetes Object
+// for the namespace with the supplied name
+func composesNamespace(desired map[resource.Name]*resource.DesiredComposed, namespace string) bool {
+	for _, dcd := range desired {
+		if !isObject(dcd.Resource.GetAPIVersion(), dcd.Resource.GetKind()) {
+			continue
+		}
+
+		apiVersion, _ := dcd.Resource.GetString("spec.forProvider.manifest.apiVersion")
+		kind, _ := dcd.Resource.GetString("spec.forProvider.manifest.kind")
+		name, _ := dcd.Resource.GetString("spec.forProvider.manifest.metadata.name")
+		if apiVersion == "v1" && kind == "Namespace" && name == namespace {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isObject returns whether the supplied apiVersion and kind are those of a cluster scoped or namespaced provider-kubernetes Object
+func isObject(apiVersion, kind string) bool {
+	if kind != providerv1alpha1.ObjectKind {
+		return false
+	}
+	return apiVersion == providerv1alpha1.SchemeGroupVersion.String() || apiVersion == namespacedObjectAPIVersion
+}
+
+// secretDataFieldPath returns the field path of the supplied key within the data of a secret
+func secretDataFieldPath(key string) string {
+	if strings.Contains(key, ".") {
+		return "data[" + key + "]"
+	}
+	return "data." + key
+}
+
+// bindingObjectName returns the name of the provider-kubernetes Object for a binding secret with the supplied namespace and name
+// Objects are cluster scoped, the name is unique per secret so that an Object for the same secret cannot be composed twice
+// names exceeding the maximum length of an Object's name are replaced by a hash
+func bindingObjectName(namespace, secretName string) string {
+	name := namespace + "." + secretName
+	if len(name) <= validation.DNS1123SubdomainMaxLength {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(name))
+	return "binding-" + hex.EncodeToString(sum[:16])
+}
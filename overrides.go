@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/function-sdk-go/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/st3v/servicebinding-decorator/input/v1alpha1"
+)
+
+// annotations claims can use to customize their binding
+const (
+	annotationBindingName      = "binding.servicebinding.io/name"
+	annotationBindingExtraKeys = "binding.servicebinding.io/extra-keys"
+	annotationBindingSkip      = "binding.servicebinding.io/skip"
+)
+
+// claimOverrideAnnotations maps the settings claims may customize to the annotations they are customized with
+var claimOverrideAnnotations = map[v1alpha1.ClaimOverride]string{
+	v1alpha1.ClaimOverrideName:      annotationBindingName,
+	v1alpha1.ClaimOverrideExtraKeys: annotationBindingExtraKeys,
+	v1alpha1.ClaimOverrideSkip:      annotationBindingSkip,
+}
+
+// applyClaimOverrides returns the supplied configuration customized by the annotations of the XR,
+// the extra binding entries the claim specifies and whether the claim skips its binding
+// annotations of settings that claims are not allowed to customize, or that are invalid, are ignored and reported as warnings
+func applyClaimOverrides(cfg v1alpha1.Config, xr *resource.Composite) (v1alpha1.Config, map[string]string, bool, []error) {
+	annotations := xr.Resource.GetAnnotations()
+	warnings := []error{}
+
+	allowed := map[v1alpha1.ClaimOverride]bool{}
+	for _, o := range cfg.ClaimOverrides {
+		allowed[o] = true
+	}
+
+	values := map[v1alpha1.ClaimOverride]string{}
+	for _, o := range []v1alpha1.ClaimOverride{v1alpha1.ClaimOverrideSkip, v1alpha1.ClaimOverrideName, v1alpha1.ClaimOverrideExtraKeys} {
+		v, ok := annotations[claimOverrideAnnotations[o]]
+		if !ok {
+			continue
+		}
+
+		if !allowed[o] {
+			warnings = append(warnings, errors.Errorf("ignoring annotation %q, claims are not allowed to customize the binding's %s", claimOverrideAnnotations[o], o))
+			continue
+		}
+
+		values[o] = v
+	}
+
+	if v, ok := values[v1alpha1.ClaimOverrideSkip]; ok {
+		skip, err := strconv.ParseBool(v)
+		if err != nil {
+			warnings = append(warnings, errors.Wrapf(err, "ignoring invalid annotation %q", annotationBindingSkip))
+		}
+		if skip {
+			return cfg, nil, true, warnings
+		}
+	}
+
+	cfg = *cfg.DeepCopy()
+
+	if name, ok := values[v1alpha1.ClaimOverrideName]; ok {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			warnings = append(warnings, errors.Errorf("ignoring invalid annotation %q: %s", annotationBindingName, strings.Join(errs, ", ")))
+		} else {
+			// the name is a valid DNS subdomain, hence it does not contain template actions and renders as is
+			setPrimarySecretName(&cfg, &v1alpha1.SecretName{Strategy: v1alpha1.SecretNameStrategyTemplate, Template: name})
+		}
+	}
+
+	var extra map[string]string
+	if v, ok := values[v1alpha1.ClaimOverrideExtraKeys]; ok {
+		if err := json.Unmarshal([]byte(v), &extra); err != nil {
+			warnings = append(warnings, errors.Wrapf(err, "ignoring invalid annotation %q", annotationBindingExtraKeys))
+			extra = nil
+		}
+	}
+
+	return cfg, extra, false, warnings
+}
+
+// setPrimarySecretName sets the naming configuration of the primary binding's secret
+func setPrimarySecretName(cfg *v1alpha1.Config, name *v1alpha1.SecretName) {
+	if len(cfg.Bindings) == 0 {
+		cfg.SecretName = name
+		return
+	}
+
+	for i := range cfg.Bindings {
+		if cfg.Bindings[i].Primary {
+			cfg.Bindings[i].SecretName = name
+			return
+		}
+	}
+
+	cfg.Bindings[0].SecretName = name
+}
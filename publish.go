@@ -0,0 +1,206 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	fnv1beta1 "github.com/crossplane/function-sdk-go/proto/v1beta1"
+	"github.com/crossplane/function-sdk-go/resource"
+	"github.com/crossplane/function-sdk-go/response"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/st3v/servicebinding-decorator/input/v1alpha1"
+)
+
+const (
+	// typeBindingReady is the type of the XR condition reporting whether the binding has been published
+	typeBindingReady xpv1.ConditionType = "BindingReady"
+
+	reasonBindingAvailable  xpv1.ConditionReason = "Available"
+	reasonBindingIncomplete xpv1.ConditionReason = "Incomplete"
+)
+
+// publishBinding publishes the binding with the supplied data in the status of the desired composite
+// if publish conditions are configured, the binding is only published once it meets them
+func publishBinding(cfg v1alpha1.Config, secretName string, data map[string][]byte, bindings []composedBinding, xr *resource.Composite, observed map[resource.Name]resource.ObservedComposed, req *fnv1beta1.RunFunctionRequest, rsp *fnv1beta1.RunFunctionResponse) *fnv1beta1.RunFunctionResponse {
+	if cfg.PublishConditions != nil {
+		return publishBindingWhenReady(cfg, secretName, data, bindings, xr, observed, req, rsp)
+	}
+	return setStatusBinding(cfg, secretName, bindings, req, rsp)
+}
+
+// publishBindingWhenReady publishes status.binding.name and status.bindings once the bindings meet the supplied publish conditions
+// every binding listed in status.bindings is checked on its own, only those meeting the conditions are listed
+// the outcome is reported via the BindingReady condition of the XR, which is only true once all bindings meet them
+func publishBindingWhenReady(cfg v1alpha1.Config, secretName string, data map[string][]byte, bindings []composedBinding, xr *resource.Composite, observed map[resource.Name]resource.ObservedComposed, req *fnv1beta1.RunFunctionRequest, rsp *fnv1beta1.RunFunctionResponse) *fnv1beta1.RunFunctionResponse {
+	pc := cfg.PublishConditions
+	unmet := []string{}
+	ready := map[string]bool{}
+	primaryReady, primaryListed := true, false
+
+	for _, b := range bindings {
+		bunmet := unmetBindingConditions(pc, b.data)
+		for _, u := range bunmet {
+			unmet = append(unmet, fmt.Sprintf("binding %q: %s", b.name, u))
+		}
+
+		ready[b.name] = len(bunmet) == 0
+		if secretName != "" && b.secretName == secretName {
+			primaryListed, primaryReady = true, ready[b.name]
+		}
+	}
+
+	if !primaryListed {
+		bunmet := unmetBindingConditions(pc, data)
+		unmet = append(unmet, bunmet...)
+		primaryReady = len(bunmet) == 0
+	}
+
+	// resources are shared by all bindings
+	if resourcesUnmet := unmetResourceConditions(pc, observed, ownResourceNames(cfg)); len(resourcesUnmet) > 0 {
+		unmet = append(unmet, resourcesUnmet...)
+		primaryReady = false
+		ready = map[string]bool{}
+	}
+
+	if len(unmet) == 0 {
+		return setStatusBinding(cfg, secretName, bindings, req, rsp, bindingReadyCondition(xr, xpv1.Condition{
+			Type:   typeBindingReady,
+			Status: corev1.ConditionTrue,
+			Reason: reasonBindingAvailable,
+		}))
+	}
+
+	msg := strings.Join(unmet, "; ")
+	response.Normalf(rsp, "binding is not ready: %s", msg)
+
+	// do not withdraw a binding that has been published before, workloads might already be bound to it
+	published, err := xr.Resource.GetString("status.binding.name")
+	if !primaryReady && (err != nil || published != secretName) {
+		secretName = ""
+	}
+
+	listed := publishedBindings(xr)
+	publishable := []composedBinding{}
+	for _, b := range bindings {
+		if ready[b.name] || listed[b.name] == b.secretName {
+			publishable = append(publishable, b)
+		}
+	}
+
+	return setStatusBinding(cfg, secretName, publishable, req, rsp, bindingReadyCondition(xr, xpv1.Condition{
+		Type:    typeBindingReady,
+		Status:  corev1.ConditionFalse,
+		Reason:  reasonBindingIncomplete,
+		Message: msg,
+	}))
+}
+
+// publishedBindings returns the secret names of the bindings the XR already lists in status.bindings by binding name
+func publishedBindings(xr *resource.Composite) map[string]string {
+	listed := []struct {
+		Name       string `json:"name"`
+		SecretName string `json:"secretName"`
+	}{}
+	if err := xr.Resource.GetValueInto("status.bindings", &listed); err != nil {
+		return map[string]string{}
+	}
+
+	published := make(map[string]string, len(listed))
+	for _, l := range listed {
+		published[l.Name] = l.SecretName
+	}
+
+	return published
+}
+
+// bindingReadyCondition returns the supplied condition with its last transition time set
+// the last transition time of the XR's observed condition is kept if the condition did not change
+func bindingReadyCondition(xr *resource.Composite, c xpv1.Condition) xpv1.Condition {
+	if observed := xr.Resource.GetCondition(c.Type); observed.Equal(c) {
+		c.LastTransitionTime = observed.LastTransitionTime
+		return c
+	}
+
+	c.LastTransitionTime = metav1.Now()
+	return c
+}
+
+// ownResourceNames returns the names of the composed resources the function composes itself for the binding
+// these are not required to be ready by default, e.g. a directly composed ServiceBinding only becomes ready once the
+// binding has been published and secretgen-controller's resources never report whether they are ready
+func ownResourceNames(cfg v1alpha1.Config) map[resource.Name]bool {
+	own := map[resource.Name]bool{
+		bindingSecretResourceName(cfg):  true,
+		secretExportResourceName(cfg):   true,
+		serviceBindingResourceName(cfg): true,
+	}
+
+	// an invalid list of bindings is reported when the bindings are composed
+	bindings, _ := bindingConfigs(cfg, nil)
+	for _, b := range bindings {
+		own[bindingSecretResourceName(b.cfg)] = true
+	}
+
+	return own
+}
+
+// unmetBindingConditions returns a description of every publish condition the supplied binding data does not meet
+func unmetBindingConditions(pc *v1alpha1.PublishConditions, data map[string][]byte) []string {
+	unmet := []string{}
+
+	// the entries required for the binding's type are checked regardless of whether validation is enabled
+	violations, _ := validateBinding(data)
+	for _, v := range violations {
+		unmet = append(unmet, v.Error())
+	}
+
+	for _, k := range pc.RequiredKeys {
+		if len(data[k]) == 0 {
+			unmet = append(unmet, fmt.Sprintf("binding does not have a %s entry", k))
+		}
+	}
+
+	return unmet
+}
+
+// unmetResourceConditions returns a description of every publish condition the composed resources do not meet
+// unless ready resources are listed explicitly, the supplied resources composed by the function itself are not checked
+func unmetResourceConditions(pc *v1alpha1.PublishConditions, observed map[resource.Name]resource.ObservedComposed, own map[resource.Name]bool) []string {
+	unmet := []string{}
+	if !pc.RequireReadyResources {
+		return unmet
+	}
+
+	names := pc.ReadyResources
+	if len(names) == 0 {
+		for name, ocr := range observed {
+			if own[name] {
+				continue
+			}
+			// secrets do not report whether they are ready
+			if ocr.Resource.GetAPIVersion() == "v1" && ocr.Resource.GetKind() == "Secret" {
+				continue
+			}
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+	}
+
+	for _, name := range names {
+		ocr, ok := observed[resource.Name(name)]
+		if !ok {
+			unmet = append(unmet, fmt.Sprintf("composed resource %q has not been observed yet", name))
+			continue
+		}
+
+		if ocr.Resource.GetCondition(xpv1.TypeReady).Status != corev1.ConditionTrue {
+			unmet = append(unmet, fmt.Sprintf("composed resource %q is not ready", name))
+		}
+	}
+
+	return unmet
+}
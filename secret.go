@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
+	"github.com/crossplane/function-sdk-go/resource"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/st3v/servicebinding-decorator/input/v1alpha1"
+)
+
+const (
+	// defaultBindingSecretResourceName is the default name of the composed resource for the binding secret
+	defaultBindingSecretResourceName = resource.Name("bindingsecret")
+
+	// bindingSecretTypePrefix is the prefix of secret types recommended by the servicebinding.io spec
+	bindingSecretTypePrefix = "servicebinding.io/"
+)
+
+// standard labels and annotations of binding secrets
+const (
+	labelClaimName       = "crossplane.io/claim-name"
+	labelClaimNamespace  = "crossplane.io/claim-namespace"
+	labelComposite       = "crossplane.io/composite"
+	labelClaimKind       = "servicebinding.io/claim-kind"
+	labelBindingType     = "servicebinding.io/type"
+	labelBindingProvider = "servicebinding.io/provider"
+
+	annotationProvisionedService = "servicebinding.io/provisioned-service"
+)
+
+// bindingSecretResourceName returns the name of the composed resource for the binding secret
+func bindingSecretResourceName(cfg v1alpha1.Config) resource.Name {
+	if cfg.ResourceName == "" {
+		return defaultBindingSecretResourceName
+	}
+	return resource.Name(cfg.ResourceName)
+}
+
+// bindingSecretType returns the type of the binding secret
+// as the type of a secret cannot be changed after its creation, the type of an already observed binding secret
+// always wins over the configured type, a warning is returned if the two differ
+// if the type is to be derived from the binding but the binding does not have a type entry, the type of an already
+// observed binding secret is kept and a warning is returned, an error is returned if it has not been observed yet
+func bindingSecretType(cfg v1alpha1.Config, details *bindingDetails, observed map[resource.Name]resource.ObservedComposed) (corev1.SecretType, []error, error) {
+	current, isObserved, err := observedSecretType(cfg, observed)
+	if err != nil {
+		return "", nil, err
+	}
+
+	desired := corev1.SecretType(cfg.SecretType)
+	if desired == "" && cfg.DeriveSecretType {
+		t, ok := details.data["type"]
+		if !ok || len(t) == 0 {
+			if !isObserved {
+				return "", nil, errors.New("cannot derive type of binding secret, binding does not have a type entry")
+			}
+			return current, []error{errors.Errorf("cannot derive type of binding secret, binding does not have a type entry, keeping type %q", secretTypeOrDefault(current))}, nil
+		}
+		desired = corev1.SecretType(bindingSecretTypePrefix + string(t))
+	}
+
+	if !isObserved {
+		return desired, nil, nil
+	}
+
+	if from, to := secretTypeOrDefault(current), secretTypeOrDefault(desired); from != to {
+		return current, []error{errors.Errorf("cannot change type of binding secret from %q to %q after it has been created", from, to)}, nil
+	}
+
+	return current, nil, nil
+}
+
+// observedSecretType returns the type of the observed binding secret and whether it has been observed
+func observedSecretType(cfg v1alpha1.Config, observed map[resource.Name]resource.ObservedComposed) (corev1.SecretType, bool, error) {
+	ocr, ok := observed[bindingSecretResourceName(cfg)]
+	if !ok {
+		return "", false, nil
+	}
+
+	// the observed binding secret is either the secret itself, the ExternalSecret creating it
+	// or the provider-kubernetes Object managing it
+	path := "spec.forProvider.manifest.type"
+	switch {
+	case ocr.Resource.GetAPIVersion() == "v1" && ocr.Resource.GetKind() == "Secret":
+		path = "type"
+	case ocr.Resource.GetAPIVersion() == externalSecretAPIVersion && ocr.Resource.GetKind() == externalSecretKind:
+		path = "spec.target.template.type"
+	}
+
+	current, err := ocr.Resource.GetString(path)
+	if err != nil && !fieldpath.IsNotFound(err) {
+		return "", false, errors.Wrap(err, "cannot get type of observed binding secret")
+	}
+
+	return corev1.SecretType(current), true, nil
+}
+
+// secretTypeOrDefault returns the supplied secret type or Opaque if it is empty
+func secretTypeOrDefault(t corev1.SecretType) corev1.SecretType {
+	if t == "" {
+		return corev1.SecretTypeOpaque
+	}
+	return t
+}
+
+// observedSecretName returns the name of the observed binding secret if the provider-kubernetes Object managing it is
+// named after it, i.e. if the binding secret is not named after the XR's UID, an empty name is returned otherwise
+// as the Object cannot be renamed, neither can the secret once the Object has been created
+func observedSecretName(cfg v1alpha1.Config, observed map[resource.Name]resource.ObservedComposed) string {
+	if composesDirectly(cfg) || !namesObjectAfterSecret(cfg) {
+		return ""
+	}
+
+	ocr, ok := observed[bindingSecretResourceName(cfg)]
+	if !ok || ocr.Resource.GetName() == "" {
+		return ""
+	}
+
+	name, _ := ocr.Resource.GetString("spec.forProvider.manifest.metadata.name")
+	return name
+}
+
+// namesObjectAfterSecret returns whether the provider-kubernetes Object managing the binding secret is named after it
+// secrets not named after the XR's UID might collide with the binding secret of another claim, naming the Object after
+// the secret lets Kubernetes reject the second Object rather than both managing the same secret
+func namesObjectAfterSecret(cfg v1alpha1.Config) bool {
+	sn := cfg.SecretName
+	return sn != nil && sn.Strategy != "" && sn.Strategy != v1alpha1.SecretNameStrategyUID
+}
+
+// bindingSecretName returns the name of the binding secret according to the supplied naming configuration
+func bindingSecretName(cfg *v1alpha1.SecretName, xr *resource.Composite, c *claim.Reference) (string, error) {
+	name := string(xr.Resource.GetUID())
+
+	if cfg != nil {
+		switch cfg.Strategy {
+		case "", v1alpha1.SecretNameStrategyUID:
+			name += cfg.Suffix
+		case v1alpha1.SecretNameStrategyClaimName:
+			name = c.Name + cfg.Suffix
+		case v1alpha1.SecretNameStrategyTemplate:
+			rendered, err := renderSecretName(cfg.Template, xr, c)
+			if err != nil {
+				return "", err
+			}
+			name = rendered
+		default:
+			return "", errors.Errorf("unknown secret name strategy %q", cfg.Strategy)
+		}
+	}
+
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", errors.Errorf("invalid binding secret name %q: %s", name, strings.Join(errs, ", "))
+	}
+
+	return name, nil
+}
+
+// renderSecretName renders the supplied template against the claim reference and the metadata, spec and status of the XR
+func renderSecretName(tmpl string, xr *resource.Composite, c *claim.Reference) (string, error) {
+	out, err := renderMetadataTemplate("secretName", tmpl, xr, c)
+	return out, errors.Wrap(err, "cannot render binding secret name template")
+}
+
+// renderMetadataTemplate renders the supplied template against the claim reference and the metadata, spec and status of the XR
+func renderMetadataTemplate(name, tmpl string, xr *resource.Composite, c *claim.Reference) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", errors.Wrap(err, "cannot parse template")
+	}
+
+	data := map[string]any{
+		"claim": map[string]any{
+			"apiVersion": c.APIVersion,
+			"kind":       c.Kind,
+			"name":       c.Name,
+			"namespace":  c.Namespace,
+		},
+		"xr": xrTemplateData(xr),
+	}
+
+	out := &strings.Builder{}
+	if err := t.Execute(out, data); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// bindingSecretMetadata returns the labels and annotations of the binding secret and its provider-kubernetes Object
+// these are the standard labels and annotations identifying the claim, the XR and the binding's type and provider,
+// followed by the supplied labels and annotations rendered against the claim and the XR
+// labels and annotations that cannot be rendered or have invalid values are skipped and reported as warnings
+func bindingSecretMetadata(cfg v1alpha1.Config, data map[string][]byte, xr *resource.Composite, c *claim.Reference) (map[string]string, map[string]string, []error) {
+	warnings := []error{}
+
+	labels, annotations := standardMetadata(data, xr, c)
+
+	for _, k := range sortedKeys(cfg.Labels) {
+		v, err := renderMetadataTemplate(k, cfg.Labels[k], xr, c)
+		if err != nil {
+			warnings = append(warnings, errors.Wrapf(err, "cannot render label %q", k))
+			continue
+		}
+
+		if errs := append(validation.IsQualifiedName(k), validation.IsValidLabelValue(v)...); len(errs) > 0 {
+			warnings = append(warnings, errors.Errorf("invalid label %q: %s", k, strings.Join(errs, ", ")))
+			continue
+		}
+
+		labels[k] = v
+	}
+
+	for _, k := range sortedKeys(cfg.Annotations) {
+		v, err := renderMetadataTemplate(k, cfg.Annotations[k], xr, c)
+		if err != nil {
+			warnings = append(warnings, errors.Wrapf(err, "cannot render annotation %q", k))
+			continue
+		}
+
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			warnings = append(warnings, errors.Errorf("invalid annotation %q: %s", k, strings.Join(errs, ", ")))
+			continue
+		}
+
+		annotations[k] = v
+	}
+
+	return labels, annotations, warnings
+}
+
+// standardMetadata returns the standard labels and annotations identifying the claim, the XR and the binding's type and provider
+func standardMetadata(data map[string][]byte, xr *resource.Composite, c *claim.Reference) (map[string]string, map[string]string) {
+	labels := map[string]string{}
+	annotations := map[string]string{}
+
+	standard := map[string]string{
+		labelComposite:       xr.Resource.GetName(),
+		labelBindingType:     string(data["type"]),
+		labelBindingProvider: string(data["provider"]),
+	}
+
+	// the provisioned service is either the claim or, for XRs without a claim, the XR itself
+	service := corev1.ObjectReference{APIVersion: c.APIVersion, Kind: c.Kind, Name: c.Name, Namespace: xr.Resource.GetNamespace()}
+	if !isComposite(c, xr) {
+		standard[labelClaimName] = c.Name
+		standard[labelClaimNamespace] = c.Namespace
+		standard[labelClaimKind] = c.Kind
+		service.Namespace = c.Namespace
+	}
+
+	for k, v := range standard {
+		// standard labels are optional, values that cannot be used as label values are silently skipped
+		if v != "" && len(validation.IsValidLabelValue(v)) == 0 {
+			labels[k] = v
+		}
+	}
+
+	if ref, err := json.Marshal(service); err == nil {
+		annotations[annotationProvisionedService] = string(ref)
+	}
+
+	return labels, annotations
+}
+
+// sortedKeys returns the keys of the supplied map in alphabetical order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
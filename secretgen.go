@@ -0,0 +1,100 @@
+package main
+
+import (
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
+	fnv1beta1 "github.com/crossplane/function-sdk-go/proto/v1beta1"
+	"github.com/crossplane/function-sdk-go/resource"
+	"github.com/crossplane/function-sdk-go/response"
+
+	"github.com/st3v/servicebinding-decorator/input/v1alpha1"
+)
+
+const (
+	// defaultSecretExportResourceName is the name of the composed resource for the SecretExport
+	// if the composed resource for the binding secret has its default name
+	defaultSecretExportResourceName = resource.Name("secretexport")
+
+	// secretgenAPIVersion is the apiVersion of composed SecretExports and SecretImports
+	secretgenAPIVersion = "secretgen.carvel.dev/v1alpha1"
+)
+
+// importsConnectionSecret returns whether the supplied connection secret is imported into the claim's namespace
+// rather than a binding secret being composed, this is the case for connection secrets in other namespaces only
+func importsConnectionSecret(cfg v1alpha1.Config, ref *xpv1.SecretReference, c *claim.Reference) bool {
+	if cfg.CrossNamespaceConnectionSecretMode != v1alpha1.CrossNamespaceConnectionSecretModeImport {
+		return false
+	}
+	return ref != nil && ref.Name != "" && ref.Namespace != "" && ref.Namespace != c.Namespace
+}
+
+// composeSecretImport adds a SecretExport for the supplied connection secret and a SecretImport importing it into
+// the claim's namespace to the supplied desired composed resources
+// the SecretImport takes the place of the binding secret and is composed as the composed resource for the binding secret
+// false is returned if the function must not proceed, in which case a fatal result has been added to the response
+func composeSecretImport(cfg v1alpha1.Config, xr *resource.Composite, c *claim.Reference, ref *xpv1.SecretReference, desiredComposed map[resource.Name]*resource.DesiredComposed, rsp *fnv1beta1.RunFunctionResponse) bool {
+	exportName, importName := secretExportResourceName(cfg), bindingSecretResourceName(cfg)
+	for _, name := range []resource.Name{exportName, importName} {
+		if _, exists := desiredComposed[name]; exists {
+			response.Fatal(rsp, errors.Errorf("cannot import connection secret, an earlier step of the pipeline already composes resource %q", name))
+			return false
+		}
+	}
+
+	labels, _ := standardMetadata(nil, xr, c)
+
+	export, err := composeManifest(cfg, xr, map[string]any{
+		"apiVersion": secretgenAPIVersion,
+		"kind":       "SecretExport",
+		"metadata": map[string]any{
+			"name":      ref.Name,
+			"namespace": ref.Namespace,
+			"labels":    labels,
+		},
+		"spec": map[string]any{
+			"toNamespace": c.Namespace,
+		},
+	}, labels)
+	if err != nil {
+		response.Fatal(rsp, errors.Wrap(err, "cannot compose SecretExport"))
+		return false
+	}
+
+	// secretgen-controller creates the imported secret with the name of the SecretImport
+	imp, err := composeManifest(cfg, xr, map[string]any{
+		"apiVersion": secretgenAPIVersion,
+		"kind":       "SecretImport",
+		"metadata": map[string]any{
+			"name":      ref.Name,
+			"namespace": c.Namespace,
+			"labels":    labels,
+		},
+		"spec": map[string]any{
+			"fromNamespace": ref.Namespace,
+		},
+	}, labels)
+	if err != nil {
+		response.Fatal(rsp, errors.Wrap(err, "cannot compose SecretImport"))
+		return false
+	}
+
+	// secretgen-controller does not report a Ready condition, Objects report whether they have been created
+	ready := resource.ReadyUnspecified
+	if composesDirectly(cfg) {
+		ready = resource.ReadyTrue
+	}
+
+	desiredComposed[exportName] = &resource.DesiredComposed{Resource: export, Ready: ready}
+	desiredComposed[importName] = &resource.DesiredComposed{Resource: imp, Ready: ready}
+
+	return true
+}
+
+// secretExportResourceName returns the name of the composed resource for the SecretExport
+func secretExportResourceName(cfg v1alpha1.Config) resource.Name {
+	if cfg.ResourceName == "" {
+		return defaultSecretExportResourceName
+	}
+	return resource.Name(cfg.ResourceName + "-" + string(defaultSecretExportResourceName))
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/unstructured/claim"
+	fnv1beta1 "github.com/crossplane/function-sdk-go/proto/v1beta1"
+	"github.com/crossplane/function-sdk-go/resource"
+	"github.com/crossplane/function-sdk-go/resource/composed"
+	"github.com/crossplane/function-sdk-go/response"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/st3v/servicebinding-decorator/input/v1alpha1"
+)
+
+const (
+	// defaultServiceBindingResourceName is the name of the composed resource for the ServiceBinding
+	// if the composed resource for the binding secret has its default name
+	defaultServiceBindingResourceName = resource.Name("servicebinding")
+
+	// serviceBindingAPIVersion is the apiVersion of composed ServiceBindings
+	serviceBindingAPIVersion = "servicebinding.io/v1beta1"
+
+	// serviceBindingKind is the kind of composed ServiceBindings
+	serviceBindingKind = "ServiceBinding"
+)
+
+// composeServiceBinding adds the claim's ServiceBinding, or the provider-kubernetes Object managing it, to the supplied desired composed resources
+// if the claim does not specify a workload or the ServiceBinding cannot be composed, this is reported in the response instead
+// false is returned if the function must not proceed, in which case a fatal result has been added to the response
+func composeServiceBinding(cfg v1alpha1.Config, xr *resource.Composite, c *claim.Reference, desiredComposed map[resource.Name]*resource.DesiredComposed, rsp *fnv1beta1.RunFunctionResponse) bool {
+	name := serviceBindingResourceName(cfg)
+	if _, exists := desiredComposed[name]; exists {
+		response.Fatal(rsp, errors.Errorf("cannot compose ServiceBinding, an earlier step of the pipeline already composes resource %q", name))
+		return false
+	}
+
+	// a ServiceBinding can only refer to a provisioned service in its own namespace
+	if isComposite(c, xr) && xr.Resource.GetNamespace() == "" {
+		response.Normal(rsp, "composite resource does not have a claim, not composing a ServiceBinding")
+		return true
+	}
+
+	object, err := serviceBindingObject(cfg, xr, c)
+	if err != nil {
+		response.Warning(rsp, errors.Wrap(err, "cannot compose ServiceBinding"))
+		return true
+	}
+
+	if object == nil {
+		response.Normal(rsp, "claim does not specify a workload, not composing a ServiceBinding")
+		return true
+	}
+
+	desiredComposed[name] = &resource.DesiredComposed{Resource: object}
+	return true
+}
+
+// serviceBindingResourceName returns the name of the composed resource for the ServiceBinding
+func serviceBindingResourceName(cfg v1alpha1.Config) resource.Name {
+	if cfg.ResourceName == "" {
+		return defaultServiceBindingResourceName
+	}
+	return resource.Name(cfg.ResourceName + "-" + string(defaultServiceBindingResourceName))
+}
+
+// serviceBindingObject returns the ServiceBinding projecting the claim's binding into its workload
+// or, unless it is composed directly, the provider-kubernetes Object managing it
+// nil is returned if the claim does not specify a workload
+func serviceBindingObject(cfg v1alpha1.Config, xr *resource.Composite, c *claim.Reference) (*composed.Unstructured, error) {
+	sb := cfg.ServiceBinding
+
+	workload, err := serviceBindingWorkload(sb.Workload, xr)
+	if err != nil || workload == nil {
+		return nil, err
+	}
+
+	name := c.Name
+	if sb.Name != "" {
+		rendered, err := renderMetadataTemplate("serviceBinding.name", sb.Name, xr, c)
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot render ServiceBinding name template")
+		}
+		name = rendered
+	}
+
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return nil, errors.Errorf("invalid ServiceBinding name %q: %s", name, strings.Join(errs, ", "))
+	}
+
+	labels, _ := standardMetadata(nil, xr, c)
+
+	spec := map[string]any{
+		// the claim is the provisioned service, it publishes the name of the binding secret in status.binding.name
+		"service": map[string]any{
+			"apiVersion": c.APIVersion,
+			"kind":       c.Kind,
+			"name":       c.Name,
+		},
+		"workload": workload,
+	}
+
+	if sb.BindingName != "" {
+		spec["name"] = sb.BindingName
+	}
+
+	if len(sb.Env) > 0 {
+		env := make([]any, 0, len(sb.Env))
+		for _, e := range sb.Env {
+			env = append(env, map[string]any{"name": e.Name, "key": e.Key})
+		}
+		spec["env"] = env
+	}
+
+	object, err := composeManifest(cfg, xr, map[string]any{
+		"apiVersion": serviceBindingAPIVersion,
+		"kind":       serviceBindingKind,
+		"metadata": map[string]any{
+			"name":      name,
+			"namespace": c.Namespace,
+			"labels":    labels,
+		},
+		"spec": spec,
+	}, labels)
+	return object, errors.Wrap(err, "cannot get composed resource for ServiceBinding")
+}
+
+// serviceBindingWorkload returns the workload reference of a ServiceBinding
+// the workload is read from the configured annotation of the XR or, if the XR does not have the annotation, from the configured field
+// nil is returned if neither specifies a workload
+func serviceBindingWorkload(cfg v1alpha1.Workload, xr *resource.Composite) (map[string]any, error) {
+	value := ""
+	if cfg.Annotation != "" {
+		value = xr.Resource.GetAnnotations()[cfg.Annotation]
+	}
+
+	if value == "" && cfg.FieldPath != "" {
+		v, err := xr.Resource.GetString(cfg.FieldPath)
+		if err != nil && !fieldpath.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "cannot get workload from field %q of composite resource", cfg.FieldPath)
+		}
+		value = v
+	}
+
+	if value == "" {
+		return nil, nil
+	}
+
+	apiVersion := cfg.APIVersion
+	if apiVersion == "" {
+		apiVersion = "apps/v1"
+	}
+
+	kind := cfg.Kind
+	if kind == "" {
+		kind = "Deployment"
+	}
+
+	workload := map[string]any{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+	}
+
+	switch cfg.ReferenceType {
+	case "", v1alpha1.WorkloadReferenceTypeName:
+		workload["name"] = value
+
+	case v1alpha1.WorkloadReferenceTypeSelector:
+		selector, err := metav1.ParseToLabelSelector(value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot parse workload selector %q", value)
+		}
+		workload["selector"] = selector
+
+	default:
+		return nil, errors.Errorf("unknown workload reference type %q", cfg.ReferenceType)
+	}
+
+	return workload, nil
+}
+
+// serviceBindingStatus returns the status of the observed ServiceBinding reflected in status.binding.serviceBinding of the XR
+// this is the ServiceBinding's name, its Ready condition and the workload it binds
+// nil is returned if the ServiceBinding has not been observed yet
+func serviceBindingStatus(cfg v1alpha1.Config, observed map[resource.Name]resource.ObservedComposed) (map[string]any, error) {
+	ocr, ok := observed[serviceBindingResourceName(cfg)]
+	if !ok {
+		return nil, nil
+	}
+
+	// provider-kubernetes reports the observed manifest of the ServiceBinding once it has been created
+	prefix := "status.atProvider.manifest."
+	if ocr.Resource.GetAPIVersion() == serviceBindingAPIVersion && ocr.Resource.GetKind() == serviceBindingKind {
+		// the ServiceBinding has been composed directly
+		prefix = ""
+	}
+
+	name, err := ocr.Resource.GetString(prefix + "metadata.name")
+	if fieldpath.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get name of observed ServiceBinding")
+	}
+
+	status := map[string]any{
+		"name":  name,
+		"ready": string(metav1.ConditionUnknown),
+	}
+
+	workload, err := ocr.Resource.GetValue(prefix + "spec.workload")
+	if err != nil && !fieldpath.IsNotFound(err) {
+		return nil, errors.Wrap(err, "cannot get workload of observed ServiceBinding")
+	}
+	if workload != nil {
+		status["workload"] = workload
+	}
+
+	conditions := []metav1.Condition{}
+	if err := ocr.Resource.GetValueInto(prefix+"status.conditions", &conditions); err != nil && !fieldpath.IsNotFound(err) {
+		return nil, errors.Wrap(err, "cannot get conditions of observed ServiceBinding")
+	}
+
+	for _, c := range conditions {
+		if c.Type != "Ready" {
+			continue
+		}
+
+		status["ready"] = string(c.Status)
+		if c.Reason != "" {
+			status["reason"] = c.Reason
+		}
+		if c.Message != "" {
+			status["message"] = c.Message
+		}
+	}
+
+	return status, nil
+}
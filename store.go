@@ -0,0 +1,111 @@
+package main
+
+import (
+	"sort"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	"github.com/crossplane/crossplane-runtime/pkg/fieldpath"
+	"github.com/crossplane/function-sdk-go/resource"
+
+	"github.com/st3v/servicebinding-decorator/input/v1alpha1"
+)
+
+// defaultStoreConfig is the name of the StoreConfig XRs publish their connection details to unless they specify one
+const defaultStoreConfig = "default"
+
+// connectionDetailsStore returns the configured secret store the XR publishes its connection details to
+// and the name it publishes them under
+// nil is returned if the XR does not publish its connection details or publishes them to a store that is not configured
+func connectionDetailsStore(cfg v1alpha1.Config, xr *resource.Composite) (*v1alpha1.ConnectionDetailsStore, string, error) {
+	if len(cfg.ConnectionDetailsStores) == 0 {
+		return nil, "", nil
+	}
+
+	to := &xpv1.PublishConnectionDetailsTo{}
+	if err := xr.Resource.GetValueInto("spec.publishConnectionDetailsTo", to); err != nil {
+		if fieldpath.IsNotFound(err) {
+			return nil, "", nil
+		}
+		return nil, "", errors.Wrap(err, "cannot get spec.publishConnectionDetailsTo of composite resource")
+	}
+
+	if to.Name == "" {
+		return nil, "", nil
+	}
+
+	storeConfig := defaultStoreConfig
+	if to.SecretStoreConfigRef != nil && to.SecretStoreConfigRef.Name != "" {
+		storeConfig = to.SecretStoreConfigRef.Name
+	}
+
+	for i := range cfg.ConnectionDetailsStores {
+		store := &cfg.ConnectionDetailsStores[i]
+
+		name := store.StoreConfig
+		if name == "" {
+			name = defaultStoreConfig
+		}
+
+		if name != storeConfig {
+			continue
+		}
+
+		switch {
+		case (store.Namespace == "") == (store.SecretStoreRef == nil):
+			return nil, "", errors.Errorf("exactly one of namespace and secretStoreRef must be specified for store config %q", name)
+		case store.SecretStoreRef != nil && store.SecretStoreRef.Name == "":
+			return nil, "", errors.Errorf("secretStoreRef.name must be specified for store config %q", name)
+		case store.Namespace != "" && composesDirectly(cfg):
+			return nil, "", errors.Errorf("values published to store config %q can only be copied by provider-kubernetes Objects, secretComposition must be Object", name)
+		}
+
+		return store, to.Name, nil
+	}
+
+	return nil, "", nil
+}
+
+// publishedRemoteSecretData splits details into the data to embed in the binding secret and the remote data an ExternalSecret
+// reads all entries read from connection details from, i.e. the connection details the XR publishes to the supplied store
+// entries the XR does not publish are omitted rather than embedded and reported as warnings, so are entries rendered from
+// templates reading values of connection details unless the ExternalSecret can render them, see remoteTemplates
+func publishedRemoteSecretData(details *bindingDetails, templates map[string]string, xr *resource.Composite, store *v1alpha1.ConnectionDetailsStore, published string) (map[string][]byte, *remoteSecretData, []error) {
+	data := map[string][]byte{}
+	remote := &remoteSecretData{
+		store:      *store.SecretStoreRef,
+		key:        store.KeyPrefix + published,
+		properties: map[string]string{},
+	}
+	warnings := []error{}
+
+	keys := make([]string, 0, len(details.data))
+	for k := range details.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if details.sensitive[k] {
+			continue
+		}
+
+		src, ok := details.sources[k]
+		if !ok {
+			data[k] = details.data[k]
+			continue
+		}
+
+		if !publishesConnectionDetail(xr, src.key, details.data[k]) {
+			warnings = append(warnings, errors.Errorf("cannot read connection detail %q of composed resource %q for binding key %q from secret store, the composite resource does not publish it", src.key, src.resource, k))
+			continue
+		}
+
+		remote.properties[k] = src.key
+	}
+
+	var templateWarnings []error
+	remote.templates, templateWarnings = remoteTemplates(details, templates, remote.properties)
+
+	return data, remote, append(warnings, templateWarnings...)
+}
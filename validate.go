@@ -0,0 +1,122 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+	fnv1beta1 "github.com/crossplane/function-sdk-go/proto/v1beta1"
+	"github.com/crossplane/function-sdk-go/response"
+
+	"github.com/st3v/servicebinding-decorator/input/v1alpha1"
+)
+
+// bindingTypeSpec specifies the entries a binding of a well-known type must and should have
+type bindingTypeSpec struct {
+	// required entries, each inner slice lists alternatives of which at least one must be present
+	required [][]string
+
+	// optional entries, each inner slice lists alternatives of which at least one should be present
+	optional [][]string
+}
+
+// bindingTypes is the registry of well-known binding types
+// the entries follow the conventions established by Spring Cloud Bindings and the servicebinding.io spec
+var bindingTypes = map[string]bindingTypeSpec{
+	"mysql": {
+		required: [][]string{{"host"}, {"port"}, {"database"}, {"username"}, {"password"}},
+		optional: [][]string{{"uri"}, {"ssl-mode"}},
+	},
+	"postgresql": {
+		required: [][]string{{"host"}, {"port"}, {"database"}, {"username"}, {"password"}},
+		optional: [][]string{{"uri"}, {"sslmode"}},
+	},
+	"redis": {
+		required: [][]string{{"host"}, {"port"}},
+		optional: [][]string{{"password"}, {"ssl"}},
+	},
+	"mongodb": {
+		required: [][]string{{"uri", "host"}},
+		optional: [][]string{{"username"}, {"password"}, {"database"}},
+	},
+	"kafka": {
+		required: [][]string{{"bootstrap-servers"}},
+		optional: [][]string{{"security.protocol"}, {"sasl.mechanism"}, {"sasl.jaas.config"}},
+	},
+	"rabbitmq": {
+		required: [][]string{{"addresses", "host"}, {"username"}, {"password"}},
+		optional: [][]string{{"port"}, {"virtual-host"}},
+	},
+	"ldap": {
+		required: [][]string{{"urls"}},
+		optional: [][]string{{"username"}, {"password"}, {"base"}},
+	},
+}
+
+// checkBinding validates the supplied binding data if validation is configured and reports the outcome
+// false is returned if the function must not proceed, in which case fatal results have been added to the response
+func checkBinding(v *v1alpha1.Validation, data map[string][]byte, rsp *fnv1beta1.RunFunctionResponse) bool {
+	if v == nil {
+		return true
+	}
+
+	violations, warnings := validateBinding(data)
+	for _, w := range warnings {
+		response.Warning(rsp, w)
+	}
+
+	if v.Strict && len(violations) > 0 {
+		for _, err := range violations {
+			response.Fatal(rsp, errors.Wrap(err, "invalid binding"))
+		}
+		return false
+	}
+
+	for _, w := range violations {
+		response.Warning(rsp, errors.Wrap(w, "invalid binding"))
+	}
+
+	return true
+}
+
+// validateBinding validates the supplied binding data
+// it returns the violations of entries the binding must have and warnings for entries it should have,
+// i.e. the provider entry and the optional entries of its type
+func validateBinding(data map[string][]byte) (violations []error, warnings []error) {
+	if len(data["provider"]) == 0 {
+		warnings = append(warnings, errors.New("binding does not have a provider entry"))
+	}
+
+	t := string(data["type"])
+	if t == "" {
+		return append(violations, errors.New("binding does not have a type entry")), warnings
+	}
+
+	spec, ok := bindingTypes[t]
+	if !ok {
+		return violations, warnings
+	}
+
+	for _, alternatives := range spec.required {
+		if !hasAnyEntry(data, alternatives) {
+			violations = append(violations, errors.Errorf("binding of type %q does not have a %s entry", t, strings.Join(alternatives, " or ")))
+		}
+	}
+
+	for _, alternatives := range spec.optional {
+		if !hasAnyEntry(data, alternatives) {
+			warnings = append(warnings, errors.Errorf("binding of type %q does not have an optional %s entry", t, strings.Join(alternatives, " or ")))
+		}
+	}
+
+	return violations, warnings
+}
+
+// hasAnyEntry returns true if data has a non-empty entry for any of the supplied keys
+func hasAnyEntry(data map[string][]byte, keys []string) bool {
+	for _, k := range keys {
+		if len(data[k]) > 0 {
+			return true
+		}
+	}
+	return false
+}
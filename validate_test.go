@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/errors"
+)
+
+// equateErrorMessages considers errors equal if their messages are equal
+var equateErrorMessages = cmp.Comparer(func(a, b error) bool {
+	return a.Error() == b.Error()
+})
+
+func TestValidateBinding(t *testing.T) {
+	type want struct {
+		violations []error
+		warnings   []error
+	}
+
+	cases := map[string]struct {
+		reason string
+		data   map[string][]byte
+		want   want
+	}{
+		"MissingType": {
+			reason: "A binding without a type entry is a violation",
+			data: map[string][]byte{
+				"provider": []byte("bitnami"),
+			},
+			want: want{
+				violations: []error{errors.New("binding does not have a type entry")},
+			},
+		},
+		"MissingProvider": {
+			reason: "A binding without a provider entry is only a warning",
+			data: map[string][]byte{
+				"type": []byte("my-type"),
+			},
+			want: want{
+				warnings: []error{errors.New("binding does not have a provider entry")},
+			},
+		},
+		"UnknownType": {
+			reason: "Entries of bindings of unknown types are not validated",
+			data: map[string][]byte{
+				"type":     []byte("my-type"),
+				"provider": []byte("my-provider"),
+			},
+		},
+		"MissingRequiredEntries": {
+			reason: "A binding of a well-known type must have the entries required for its type",
+			data: map[string][]byte{
+				"type":         []byte("rabbitmq"),
+				"provider":     []byte("bitnami"),
+				"host":         []byte("my-host"),
+				"port":         []byte("5672"),
+				"virtual-host": []byte("my-vhost"),
+				"username":     []byte("my-user"),
+			},
+			want: want{
+				violations: []error{errors.New(`binding of type "rabbitmq" does not have a password entry`)},
+			},
+		},
+		"AlternativeEntries": {
+			reason: "Any of the alternatives of a required entry satisfies the requirement",
+			data: map[string][]byte{
+				"type":     []byte("mongodb"),
+				"provider": []byte("bitnami"),
+				"uri":      []byte("mongodb://my-host"),
+				"username": []byte("my-user"),
+				"password": []byte("my-password"),
+				"database": []byte("my-database"),
+			},
+		},
+		"MissingOptionalEntries": {
+			reason: "A binding of a well-known type without the optional entries of its type is only a warning",
+			data: map[string][]byte{
+				"type":              []byte("kafka"),
+				"provider":          []byte("bitnami"),
+				"bootstrap-servers": []byte("my-host:9092"),
+				"security.protocol": []byte("SASL_SSL"),
+			},
+			want: want{
+				warnings: []error{
+					errors.New(`binding of type "kafka" does not have an optional sasl.mechanism entry`),
+					errors.New(`binding of type "kafka" does not have an optional sasl.jaas.config entry`),
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			violations, warnings := validateBinding(tc.data)
+
+			if diff := cmp.Diff(tc.want.violations, violations, equateErrorMessages); diff != "" {
+				t.Errorf("%s\nvalidateBinding(...): -want violations, +got violations:\n%s", tc.reason, diff)
+			}
+
+			if diff := cmp.Diff(tc.want.warnings, warnings, equateErrorMessages); diff != "" {
+				t.Errorf("%s\nvalidateBinding(...): -want warnings, +got warnings:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}